@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"infra/batch"
+	"infra/logging"
+	"infra/teams"
+)
+
+// batchFlags are the flags shared by the batch and sweep commands.
+type batchFlags struct {
+	set     *flag.FlagSet
+	games   *uint
+	seed    *int64
+	workers *uint
+	output  *string
+	debug   *bool
+	config  configFlags
+}
+
+func newBatchFlags(command string, gamesUsage string) batchFlags {
+	set := flag.NewFlagSet(command, flag.ExitOnError)
+	return batchFlags{
+		config:  addConfigFlags(set),
+		set:     set,
+		games:   set.Uint("n", 100, gamesUsage),
+		seed:    set.Int64("seed", 1, "Seed of the first game; game i is played with seed+i"),
+		workers: set.Uint("workers", uint(runtime.NumCPU()), "Number of games to play in parallel"),
+		output:  set.String("o", "", "Write the results to this file, as JSON or CSV depending on its extension"),
+		debug:   set.Bool("d", false, "Show the engine logs of every game"),
+	}
+}
+
+func (f batchFlags) options() batch.Options {
+	logging.InitLogger(false, *f.debug)
+	if !*f.debug {
+		logging.SetLevel(logging.Error)
+	}
+	return batch.Options{Games: *f.games, Seed: *f.seed, Workers: *f.workers}
+}
+
+// runBatch implements the `batch` command: play many seeded games and report aggregate results.
+func runBatch(args []string) {
+	flags := newBatchFlags("batch", "Number of games to play")
+	_ = flags.set.Parse(args)
+	opts := flags.options()
+
+	summary := batch.Run(opts, loadGameConfig(flags.config, nil), teams.Strategies())
+
+	_ = summary.WriteTable(os.Stdout)
+	if *flags.output != "" {
+		writeResults(*flags.output, summary.WriteJSON, summary.WriteCSV)
+	}
+}
+
+// runSweep implements the `sweep` command: play a batch for every combination of the given
+// parameter values, e.g. `sweep -n 50 THRESHOLD_PCT=0.4:0.8:0.1 LEVELS=20,40,60`.
+func runSweep(args []string) {
+	flags := newBatchFlags("sweep", "Number of games to play per combination of parameters")
+	flags.set.Usage = func() {
+		fmt.Fprintf(flags.set.Output(), "Usage: %s sweep [flags] NAME=a,b,c NAME=start:stop:step ...\n", os.Args[0])
+		flags.set.PrintDefaults()
+	}
+	_ = flags.set.Parse(args)
+	opts := flags.options()
+
+	parameters := make([]batch.Parameter, 0, flags.set.NArg())
+	for _, arg := range flags.set.Args() {
+		parameter, err := batch.ParseParameter(arg)
+		if err != nil {
+			logging.Log(logging.Error, nil, err.Error())
+			os.Exit(2)
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	result, err := batch.Sweep(opts, loadGameConfig(flags.config, nil), parameters, teams.Strategies())
+	if err != nil {
+		logging.Log(logging.Error, nil, err.Error())
+		os.Exit(2)
+	}
+
+	if *flags.output == "" {
+		_ = result.WriteCSV(os.Stdout)
+		return
+	}
+	writeResults(*flags.output, result.WriteJSON, result.WriteCSV)
+}
+
+// writeResults writes to path in the format given by its extension, exiting on failure.
+func writeResults(path string, writeJSON func(io.Writer) error, writeCSV func(io.Writer) error) {
+	write := writeCSV
+	switch filepath.Ext(path) {
+	case ".csv":
+	case ".json":
+		write = writeJSON
+	default:
+		logging.Log(logging.Error, nil, fmt.Sprintf("Unsupported output format %q, use .json or .csv", filepath.Ext(path)))
+		os.Exit(2)
+	}
+
+	file, err := os.Create(path)
+	if err == nil {
+		err = write(file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		logging.Log(logging.Error, nil, fmt.Sprintf("Unable to write %s: %v", path, err))
+		os.Exit(1)
+	}
+}
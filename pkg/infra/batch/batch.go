@@ -0,0 +1,92 @@
+package batch
+
+import (
+	"sync"
+
+	"infra/config"
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/engine"
+	"infra/logging"
+)
+
+// Options controls how many games a batch plays and how they are seeded.
+type Options struct {
+	// Games is the number of games to play.
+	Games uint
+	// Seed is the seed of the first game; game i is played with Seed+i.
+	Seed int64
+	// Workers is the number of games played in parallel.
+	Workers uint
+}
+
+// GameResult is the outcome of a single game in a batch.
+type GameResult struct {
+	Seed      int64
+	Won       bool
+	Level     uint
+	Survivors map[string]uint
+	// LeaderTenures holds the number of consecutive levels each successive leader held office for.
+	LeaderTenures []uint
+}
+
+// Run plays opts.Games games of gameConfig across the seed range and summarises them.
+// Every game gets freshly constructed strategies, so the strategy constructors must not share state.
+func Run(opts Options, gameConfig config.GameConfig, strategies map[commons.ID]func() agent.Strategy) Summary {
+	workers := opts.Workers
+	if workers == 0 {
+		workers = 1
+	}
+
+	seeds := make(chan int64)
+	results := make([]GameResult, opts.Games)
+	var wg sync.WaitGroup
+	for w := uint(0); w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seed := range seeds {
+				results[seed-opts.Seed] = playGame(gameConfig, seed, strategies)
+			}
+		}()
+	}
+
+	for i := uint(0); i < opts.Games; i++ {
+		seeds <- opts.Seed + int64(i)
+	}
+	close(seeds)
+	wg.Wait()
+
+	return Summarise(results, commons.SortedKeys(strategies))
+}
+
+func playGame(gameConfig config.GameConfig, seed int64, strategies map[commons.ID]func() agent.Strategy) GameResult {
+	gameConfig.Seed = seed
+	game := engine.NewGame(gameConfig, strategies)
+	outcome, gameLog := game.Run()
+
+	logging.Log(logging.Debug, logging.LogField{"seed": seed, "won": outcome == logging.Win, "level": game.Level()}, "Batch game finished")
+
+	return GameResult{
+		Seed:          seed,
+		Won:           outcome == logging.Win,
+		Level:         game.Level(),
+		Survivors:     game.Survivors(),
+		LeaderTenures: leaderTenures(gameLog),
+	}
+}
+
+// leaderTenures splits the levels of a game into runs with the same leader.
+func leaderTenures(gameLog logging.GameLog) []uint {
+	tenures := make([]uint, 0)
+	var current commons.ID
+	for _, level := range gameLog.Levels {
+		leader := level.LevelStats.LeaderAfterElection
+		if len(tenures) == 0 || leader != current || level.ElectionStage.Occurred {
+			tenures = append(tenures, 0)
+			current = leader
+		}
+		tenures[len(tenures)-1]++
+	}
+	return tenures
+}
@@ -0,0 +1,133 @@
+package batch_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"infra/batch"
+	"infra/config"
+	"infra/teams"
+
+	_ "infra/teams/team1"
+)
+
+func TestNewStatistic(t *testing.T) {
+	t.Parallel()
+
+	stat := batch.NewStatistic([]float64{4, 1, 3, 2})
+	if stat.Mean != 2.5 || stat.Median != 2.5 {
+		t.Errorf("NewStatistic({4, 1, 3, 2}) = %+v; want mean 2.5 and median 2.5", stat)
+	}
+	if stat.CI.Low >= stat.Mean || stat.CI.High <= stat.Mean {
+		t.Errorf("NewStatistic({4, 1, 3, 2}).CI = %+v; want an interval around the mean", stat.CI)
+	}
+}
+
+func TestWilsonInterval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		successes uint
+		trials    uint
+		want      batch.Interval
+	}{
+		{"no trials", 0, 0, batch.Interval{}},
+		{"no successes", 0, 10, batch.Interval{Low: 0, High: 0.2775}},
+		{"half", 50, 100, batch.Interval{Low: 0.4038, High: 0.5962}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := batch.WilsonInterval(tt.successes, tt.trials)
+			if math.Abs(got.Low-tt.want.Low) > 1e-4 || math.Abs(got.High-tt.want.High) > 1e-4 {
+				t.Errorf("WilsonInterval(%d, %d) = %+v; want %+v", tt.successes, tt.trials, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseParameter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{"LEVELS=20,40,60", []string{"20", "40", "60"}, false},
+		{"THRESHOLD_PCT=0.4:0.8:0.1", []string{"0.4", "0.5", "0.6", "0.7", "0.8"}, false},
+		{"BASE_STAMINA=1000:2000:500", []string{"1000", "1500", "2000"}, false},
+		{"LEVELS", nil, true},
+		{"LEVELS=1:2", nil, true},
+		{"LEVELS=2:1:1", nil, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			got, err := batch.ParseParameter(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseParameter(%q) error = %v; wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && strings.Join(got.Values, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ParseParameter(%q) = %v; want %v", tt.in, got.Values, tt.want)
+			}
+		})
+	}
+}
+
+// smallConfig is a valid config for short games between RANDOM and TEAM1 agents.
+func smallConfig() config.GameConfig {
+	return config.GameConfig{
+		NumLevels:              3,
+		StartingHealthPoints:   1000,
+		StartingAttackStrength: 20,
+		StartingShieldStrength: 20,
+		ThresholdPercentage:    0.6,
+		Stamina:                2000,
+		VotingStrategy:         1,
+		VotingPreferences:      2,
+		TieBreak:               config.TieBreakRandom,
+		StalemateRule:          config.StalemateLose,
+		DamageModel:            config.DamageEven,
+		Recall:                 config.RecallRules{Outcome: config.RecallElection},
+		Succession:             config.SuccessionRunnerUp,
+		StaminaModel:           config.StaminaModel{ItemWeight: 1, CowerStamina: 1, CowerHpPct: 1},
+		MonstersPerLevel:       1,
+		TradeRounds:            2,
+		TradeRoundLimit:        1,
+		AgentQuantities:        map[string]uint{"RANDOM": 10, "TEAM1": 20},
+	}
+}
+
+// TestParallelTeams plays games of every team side by side. Run it with -race, as make test_race does, to catch
+// teams sharing state between games.
+func TestParallelTeams(t *testing.T) {
+	t.Parallel()
+
+	summary := batch.Run(batch.Options{Games: 4, Seed: 1, Workers: 4}, smallConfig(), teams.Strategies())
+	if summary.Games != 4 {
+		t.Errorf("played %d games; want 4", summary.Games)
+	}
+}
+
+func TestSweepRejectsInvalidPoints(t *testing.T) {
+	t.Parallel()
+
+	if err := smallConfig().Validate([]string{"RANDOM", "TEAM1"}); err != nil {
+		t.Fatalf("the base config is invalid: %v", err)
+	}
+	tests := []batch.Parameter{
+		{Name: "THRESHOLD_PCT", Values: []string{"0.5", "1.5"}},
+		{Name: "VOTING_PREFERENCES", Values: []string{"2", "0"}},
+	}
+	for _, parameter := range tests {
+		_, err := batch.Sweep(batch.Options{Games: 1, Seed: 1, Workers: 1}, smallConfig(), []batch.Parameter{parameter}, teams.Strategies())
+		if err == nil || !strings.Contains(err.Error(), parameter.Name) {
+			t.Errorf("sweeping %s over %v gave error %v; want one about %s", parameter.Name, parameter.Values, err, parameter.Name)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+// z-score for a two-sided 95% confidence interval
+const z95 = 1.959964
+
+// Interval is a 95% confidence interval.
+type Interval struct {
+	Low  float64
+	High float64
+}
+
+// Statistic summarises one measured quantity across the games of a batch.
+type Statistic struct {
+	Mean   float64
+	Median float64
+	CI     Interval
+}
+
+// TeamSummary summarises the survivors of one team across a batch.
+type TeamSummary struct {
+	Name      string
+	Survivors Statistic
+}
+
+// Summary aggregates the results of a batch.
+type Summary struct {
+	Games        uint
+	Wins         uint
+	WinRate      float64
+	WinRateCI    Interval
+	Level        Statistic
+	LeaderTenure Statistic
+	Teams        []TeamSummary
+	Results      []GameResult
+}
+
+// Summarise aggregates per-game results. Teams lists the team names to report survivors for,
+// so that teams which were wiped out in every game still appear.
+func Summarise(results []GameResult, teams []string) Summary {
+	summary := Summary{Games: uint(len(results)), Results: results}
+
+	levels := make([]float64, len(results))
+	tenures := make([]float64, 0)
+	for i, result := range results {
+		if result.Won {
+			summary.Wins++
+		}
+		levels[i] = float64(result.Level)
+		for _, tenure := range result.LeaderTenures {
+			tenures = append(tenures, float64(tenure))
+		}
+	}
+
+	if summary.Games > 0 {
+		summary.WinRate = float64(summary.Wins) / float64(summary.Games)
+	}
+	summary.WinRateCI = WilsonInterval(summary.Wins, summary.Games)
+	summary.Level = NewStatistic(levels)
+	summary.LeaderTenure = NewStatistic(tenures)
+
+	for _, team := range teams {
+		survivors := make([]float64, len(results))
+		for i, result := range results {
+			survivors[i] = float64(result.Survivors[team])
+		}
+		summary.Teams = append(summary.Teams, TeamSummary{Name: team, Survivors: NewStatistic(survivors)})
+	}
+
+	return summary
+}
+
+// NewStatistic computes the mean, median and a normal-approximation 95% confidence interval for the mean.
+func NewStatistic(samples []float64) Statistic {
+	n := float64(len(samples))
+	if n == 0 {
+		return Statistic{}
+	}
+
+	sum := 0.0
+	for _, s := range samples {
+		sum += s
+	}
+	mean := sum / n
+
+	halfWidth := 0.0
+	if n > 1 {
+		squares := 0.0
+		for _, s := range samples {
+			squares += (s - mean) * (s - mean)
+		}
+		halfWidth = z95 * math.Sqrt(squares/(n-1)) / math.Sqrt(n)
+	}
+
+	return Statistic{
+		Mean:   mean,
+		Median: median(samples),
+		CI:     Interval{Low: mean - halfWidth, High: mean + halfWidth},
+	}
+}
+
+func median(samples []float64) float64 {
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// WilsonInterval is the 95% Wilson score interval for a proportion of successes out of trials.
+// Unlike the normal approximation it behaves sensibly for win rates close to 0 or 1.
+func WilsonInterval(successes uint, trials uint) Interval {
+	if trials == 0 {
+		return Interval{}
+	}
+	n := float64(trials)
+	p := float64(successes) / n
+	denominator := 1 + z95*z95/n
+	centre := (p + z95*z95/(2*n)) / denominator
+	halfWidth := z95 * math.Sqrt(p*(1-p)/n+z95*z95/(4*n*n)) / denominator
+	return Interval{Low: math.Max(0, centre-halfWidth), High: math.Min(1, centre+halfWidth)}
+}
+
+// WriteTable prints a human-readable summary.
+func (s Summary) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "games\t%d\n", s.Games)
+	fmt.Fprintf(tw, "win rate\t%.3f\t[%.3f, %.3f]\n", s.WinRate, s.WinRateCI.Low, s.WinRateCI.High)
+	fmt.Fprintln(tw, "\tmean\tmedian\t95% CI")
+	writeStatisticRow(tw, "level reached", s.Level)
+	writeStatisticRow(tw, "leader tenure", s.LeaderTenure)
+	for _, team := range s.Teams {
+		writeStatisticRow(tw, "survivors "+team.Name, team.Survivors)
+	}
+	return tw.Flush()
+}
+
+func writeStatisticRow(w io.Writer, name string, stat Statistic) {
+	fmt.Fprintf(w, "%s\t%.2f\t%.2f\t[%.2f, %.2f]\n", name, stat.Mean, stat.Median, stat.CI.Low, stat.CI.High)
+}
+
+// WriteJSON writes the summary, including every game's result, as JSON.
+func (s Summary) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+var summaryHeader = []string{"metric", "mean", "median", "ci_low", "ci_high"}
+
+// WriteCSV writes one row per summarised metric.
+func (s Summary) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(append([][]string{summaryHeader}, s.rows()...)); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func (s Summary) rows() [][]string {
+	rows := [][]string{
+		{"win_rate", formatFloat(s.WinRate), "", formatFloat(s.WinRateCI.Low), formatFloat(s.WinRateCI.High)},
+		statisticRow("level", s.Level),
+		statisticRow("leader_tenure", s.LeaderTenure),
+	}
+	for _, team := range s.Teams {
+		rows = append(rows, statisticRow("survivors_"+team.Name, team.Survivors))
+	}
+	return rows
+}
+
+func statisticRow(name string, stat Statistic) []string {
+	return []string{name, formatFloat(stat.Mean), formatFloat(stat.Median), formatFloat(stat.CI.Low), formatFloat(stat.CI.High)}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
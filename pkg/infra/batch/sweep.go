@@ -0,0 +1,146 @@
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"infra/config"
+	"infra/game/agent"
+	"infra/game/commons"
+)
+
+// Parameter is a config parameter, named by its environment variable, and the values to sweep it over.
+type Parameter struct {
+	Name   string
+	Values []string
+}
+
+// ParseParameter parses NAME=a,b,c (a list of values) or NAME=start:stop:step (an inclusive numeric range).
+func ParseParameter(s string) (Parameter, error) {
+	name, values, ok := strings.Cut(s, "=")
+	if !ok || name == "" || values == "" {
+		return Parameter{}, fmt.Errorf("invalid parameter %q, expected NAME=a,b,c or NAME=start:stop:step", s)
+	}
+
+	if !strings.Contains(values, ":") {
+		return Parameter{Name: name, Values: strings.Split(values, ",")}, nil
+	}
+
+	bounds := strings.Split(values, ":")
+	if len(bounds) != 3 {
+		return Parameter{}, fmt.Errorf("invalid range %q for %s, expected start:stop:step", values, name)
+	}
+	var numbers [3]float64
+	precision := 0
+	for i, bound := range bounds {
+		if _, fraction, ok := strings.Cut(bound, "."); ok && len(fraction) > precision {
+			precision = len(fraction)
+		}
+		f, err := strconv.ParseFloat(bound, 64)
+		if err != nil {
+			return Parameter{}, fmt.Errorf("invalid range %q for %s: %w", values, name, err)
+		}
+		numbers[i] = f
+	}
+	start, stop, step := numbers[0], numbers[1], numbers[2]
+	if step <= 0 || stop < start {
+		return Parameter{}, fmt.Errorf("invalid range %q for %s, step must be positive and stop at least start", values, name)
+	}
+
+	parameter := Parameter{Name: name}
+	// count steps rather than accumulating, so that float error doesn't drop the last value
+	steps := int((stop-start)/step + 1e-9)
+	for i := 0; i <= steps; i++ {
+		parameter.Values = append(parameter.Values, strconv.FormatFloat(start+float64(i)*step, 'f', precision, 64))
+	}
+	return parameter, nil
+}
+
+// Point is one combination of parameter values and the summary of the games played with it.
+type Point struct {
+	Parameters map[string]string
+	Summary    Summary
+}
+
+// SweepResult holds every point of a sweep, in the order the parameters' values were given.
+type SweepResult struct {
+	Parameters []string
+	Points     []Point
+}
+
+// Sweep runs a batch for every combination of the parameters' values. Every point is played
+// with the same seeds, so differences between points are down to the parameters alone.
+func Sweep(opts Options, base config.GameConfig, parameters []Parameter, strategies map[commons.ID]func() agent.Strategy) (SweepResult, error) {
+	combinations := []map[string]string{{}}
+	for _, parameter := range parameters {
+		next := make([]map[string]string, 0, len(combinations)*len(parameter.Values))
+		for _, combination := range combinations {
+			for _, value := range parameter.Values {
+				extended := make(map[string]string, len(combination)+1)
+				for k, v := range combination {
+					extended[k] = v
+				}
+				extended[parameter.Name] = value
+				next = append(next, extended)
+			}
+		}
+		combinations = next
+	}
+
+	// build and check every config up front so that a bad value fails before any game is played
+	configs := make([]config.GameConfig, len(combinations))
+	for i, combination := range combinations {
+		configs[i] = base
+		for _, parameter := range parameters {
+			if err := configs[i].SetParameter(parameter.Name, combination[parameter.Name]); err != nil {
+				return SweepResult{}, err
+			}
+		}
+		if err := configs[i].Validate(commons.SortedKeys(strategies)); err != nil {
+			return SweepResult{}, fmt.Errorf("point %v: %w", combination, err)
+		}
+	}
+
+	result := SweepResult{}
+	for _, parameter := range parameters {
+		result.Parameters = append(result.Parameters, parameter.Name)
+	}
+	for i, combination := range combinations {
+		result.Points = append(result.Points, Point{Parameters: combination, Summary: Run(opts, configs[i], strategies)})
+	}
+	return result, nil
+}
+
+// WriteJSON writes every point, including every game's result, as JSON.
+func (r SweepResult) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes one row per point and summarised metric, keyed by the parameter values.
+func (r SweepResult) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := append(append([]string{}, r.Parameters...), "games")
+	if err := writer.Write(append(header, summaryHeader...)); err != nil {
+		return err
+	}
+	for _, point := range r.Points {
+		key := make([]string, 0, len(r.Parameters)+1)
+		for _, name := range r.Parameters {
+			key = append(key, point.Parameters[name])
+		}
+		key = append(key, strconv.FormatUint(uint64(point.Summary.Games), 10))
+		for _, row := range point.Summary.rows() {
+			if err := writer.Write(append(append([]string{}, key...), row...)); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
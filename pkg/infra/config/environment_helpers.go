@@ -8,9 +8,10 @@ import (
 )
 
 func EnvToUint(key string, def uint) uint {
-	levels, err := strconv.ParseUint(os.Getenv(key), 10, 0)
+	value, set := os.LookupEnv(key)
+	levels, err := strconv.ParseUint(value, 10, 0)
 	if err != nil {
-		logging.Log(logging.Warn, nil, fmt.Sprintf("%s unset, defaulting to %d\n", key, def))
+		warnDefault(key, value, set, def)
 
 		return def
 	}
@@ -18,10 +19,23 @@ func EnvToUint(key string, def uint) uint {
 	return uint(levels)
 }
 
+func EnvToInt64(key string, def int64) int64 {
+	value, set := os.LookupEnv(key)
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		warnDefault(key, value, set, def)
+
+		return def
+	}
+
+	return parsed
+}
+
 func EnvToFloat(key string, def float32) float32 {
-	levels, err := strconv.ParseFloat(os.Getenv(key), 32)
+	value, set := os.LookupEnv(key)
+	levels, err := strconv.ParseFloat(value, 32)
 	if err != nil {
-		logging.Log(logging.Warn, nil, fmt.Sprintf("%s unset, defaulting to %f\n", key, def))
+		warnDefault(key, value, set, def)
 
 		return def
 	}
@@ -32,7 +46,7 @@ func EnvToFloat(key string, def float32) float32 {
 func EnvToString(key string, def string) string {
 	s := os.Getenv(key)
 	if s == "" {
-		logging.Log(logging.Warn, nil, fmt.Sprintf("%s unset, defaulting to %s\n", key, def))
+		warnDefault(key, s, false, def)
 
 		return def
 	}
@@ -41,11 +55,22 @@ func EnvToString(key string, def string) string {
 }
 
 func EnvToBool(key string, def bool) bool {
-	b, err := strconv.ParseBool(os.Getenv(key))
+	value, set := os.LookupEnv(key)
+	b, err := strconv.ParseBool(value)
 	if err != nil {
-		logging.Log(logging.Warn, nil, fmt.Sprintf("%s unset, defaulting to %d\n", key, def))
+		warnDefault(key, value, set, def)
 
 		return def
 	}
 	return b
 }
+
+// warnDefault reports that def is used for key, either because it is unset or because its value could not be parsed,
+// which is most likely a typo and so reported as an error.
+func warnDefault(key string, value string, set bool, def any) {
+	if set {
+		logging.Log(logging.Error, nil, fmt.Sprintf("%s=%q is not valid, defaulting to %v", key, value, def))
+		return
+	}
+	logging.Log(logging.Warn, nil, fmt.Sprintf("%s unset, defaulting to %v", key, def))
+}
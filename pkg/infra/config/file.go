@@ -0,0 +1,65 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"infra/game/commons"
+)
+
+// File is the layout of a config file, e.g.
+//
+//	{
+//		"Game": {"LEVELS": 40, "THRESHOLD_PCT": 0.5, "STALEMATE_RULE": "retreat", "TRADE_ROUNDS": 3},
+//		"Agents": {"RANDOM": 50, "TEAM1": 50}
+//	}
+//
+// Game parameters, including those of the stages, are named after their environment variables and take the
+// same values, see SetParameter. Agents holds the number of agents of each team, replacing AGENT_<NAME>_QUANTITY.
+// Settings missing from the file are left as they are.
+type File struct {
+	Game   map[string]json.RawMessage
+	Agents map[string]uint
+}
+
+// LoadFile applies the config file at path to c. Unknown fields and parameters are errors, so that typos
+// are not mistaken for settings.
+func (c *GameConfig) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var file File
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	for _, key := range commons.SortedKeys(file.Game) {
+		if strings.HasPrefix(key, "AGENT_") {
+			return fmt.Errorf("%s: set agent quantities under Agents, not %s", path, key)
+		}
+		if err := c.SetParameter(key, parameterValue(file.Game[key])); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	for _, name := range commons.SortedKeys(file.Agents) {
+		if err := c.SetParameter("AGENT_"+name+"_QUANTITY", fmt.Sprint(file.Agents[name])); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// parameterValue returns a JSON value as SetParameter takes it: strings unquoted, numbers and booleans as written.
+func parameterValue(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(bytes.TrimSpace(raw))
+}
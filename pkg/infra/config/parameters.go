@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"infra/game/monster"
+)
+
+// AgentQuantity returns how many agents of the named team to create. Quantities set on the config
+// take precedence over the AGENT_<NAME>_QUANTITY environment variable.
+func (c GameConfig) AgentQuantity(agentName string) uint {
+	if quantity, ok := c.AgentQuantities[agentName]; ok {
+		return quantity
+	}
+	return EnvToUint("AGENT_"+agentName+"_QUANTITY", 100)
+}
+
+// SetParameter sets the field named by its environment variable (e.g. THRESHOLD_PCT or AGENT_RANDOM_QUANTITY)
+// to value, so that tools can vary the config without touching the environment.
+func (c *GameConfig) SetParameter(key string, value string) error {
+	if strings.HasPrefix(key, "AGENT_") && strings.HasSuffix(key, "_QUANTITY") {
+		quantity, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		// copy so that configs derived from the same base don't share quantities
+		quantities := make(map[string]uint, len(c.AgentQuantities)+1)
+		for name, q := range c.AgentQuantities {
+			quantities[name] = q
+		}
+		quantities[strings.TrimSuffix(strings.TrimPrefix(key, "AGENT_"), "_QUANTITY")] = uint(quantity)
+		c.AgentQuantities = quantities
+		return nil
+	}
+
+	var err error
+	switch key {
+	case "LEVELS":
+		err = parseUint(value, &c.NumLevels)
+	case "STARTING_HP":
+		err = parseUint(value, &c.StartingHealthPoints)
+	case "STARTING_ATTACK":
+		err = parseUint(value, &c.StartingAttackStrength)
+	case "STARTING_SHIELD":
+		err = parseUint(value, &c.StartingShieldStrength)
+	case "THRESHOLD_PCT":
+		var f float64
+		f, err = strconv.ParseFloat(value, 32)
+		c.ThresholdPercentage = float32(f)
+	case "BASE_STAMINA":
+		err = parseUint(value, &c.Stamina)
+	case "VOTING_STRATEGY":
+		err = parseUint(value, &c.VotingStrategy)
+	case "VOTING_PREFERENCES":
+		err = parseUint(value, &c.VotingPreferences)
+	case "ELECTION_TIE_BREAK":
+		c.TieBreak = TieBreak(value)
+	case "ANONYMOUS_BALLOTS":
+		c.AnonymousBallots, err = strconv.ParseBool(value)
+	case "RECALL_QUORUM_PCT":
+		err = parseUint(value, &c.Recall.Quorum)
+	case "RECALL_SUPERMAJORITY_PCT":
+		err = parseUint(value, &c.Recall.Supermajority)
+	case "RECALL_COUNT_ABSTENTIONS":
+		c.Recall.CountAbstentions, err = strconv.ParseBool(value)
+	case "RECALL_COOLDOWN_LEVELS":
+		err = parseUint(value, &c.Recall.Cooldown)
+	case "RECALL_OUTCOME":
+		c.Recall.Outcome = RecallOutcome(value)
+	case "LEADER_SUCCESSION":
+		c.Succession = Succession(value)
+	case "DEFECTION":
+		c.Defection, err = strconv.ParseBool(value)
+	case "MAX_DISCUSSION_ROUNDS":
+		err = parseUint(value, &c.MaxDiscussionRounds)
+	case "MAX_DISCUSSION_MESSAGES":
+		err = parseUint(value, &c.MaxDiscussionMessages)
+	case "MAX_FIGHT_ROUNDS":
+		err = parseUint(value, &c.MaxFightRounds)
+	case "STALEMATE_RULE":
+		c.StalemateRule = StalemateRule(value)
+	case "ENRAGE_PCT":
+		err = parseUint(value, &c.EnragePercentage)
+	case "DAMAGE_MODEL":
+		c.DamageModel = DamageModel(value)
+	case "STAMINA_ATTACK_COST":
+		err = parseUint(value, &c.StaminaModel.AttackCost)
+	case "STAMINA_DEFEND_COST":
+		err = parseUint(value, &c.StaminaModel.DefendCost)
+	case "STAMINA_ITEM_WEIGHT":
+		var f float64
+		f, err = strconv.ParseFloat(value, 32)
+		c.StaminaModel.ItemWeight = float32(f)
+	case "COWER_STAMINA":
+		err = parseUint(value, &c.StaminaModel.CowerStamina)
+	case "COWER_HP_PCT":
+		err = parseUint(value, &c.StaminaModel.CowerHpPct)
+	case "STAMINA_ROUND_REGEN":
+		err = parseUint(value, &c.StaminaModel.RoundRegen)
+	case "STAMINA_LEVEL_REGEN":
+		err = parseUint(value, &c.StaminaModel.LevelRegen)
+	case "TRADE_ROUNDS":
+		err = parseUint(value, &c.TradeRounds)
+	case "TRADE_ROUND_LIMIT":
+		err = parseUint(value, &c.TradeRoundLimit)
+	case "MONSTERS_PER_LEVEL":
+		err = parseUint(value, &c.MonstersPerLevel)
+	case "FIGHT_HISTORY_LEVELS":
+		err = parseUint(value, &c.FightHistoryLevels)
+	case "MONSTER_FILE":
+		c.Monsters, err = monster.Load(value)
+	case "DECISION_TIMEOUT_MS":
+		var ms uint
+		err = parseUint(value, &ms)
+		c.DecisionTimeout = time.Duration(ms) * time.Millisecond
+	case "SEED":
+		c.Seed, err = strconv.ParseInt(value, 10, 64)
+	default:
+		return fmt.Errorf("unknown game parameter %s", key)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+func parseUint(value string, field *uint) error {
+	u, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return err
+	}
+	*field = uint(u)
+	return nil
+}
@@ -1,5 +1,11 @@
 package config
 
+import (
+	"time"
+
+	"infra/game/monster"
+)
+
 type GameConfig struct {
 	NumLevels              uint
 	StartingHealthPoints   uint
@@ -11,4 +17,180 @@ type GameConfig struct {
 	VotingStrategy         uint
 	VotingPreferences      uint
 	Defection              bool
+	Seed                   int64
+	// TieBreak is how an election settles a tie between candidates, empty meaning TieBreakRandom.
+	TieBreak TieBreak
+	// AnonymousBallots leaves the voters' IDs out of the ballots recorded in the game log.
+	AnonymousBallots bool
+	// Recall is how the confidence vote held on the leader at the start of each level of its term is counted,
+	// and what follows when it goes against the leader.
+	Recall RecallRules
+	// Succession is who leads when the leader dies during its term and its manifesto names no living deputy,
+	// empty meaning SuccessionRunnerUp.
+	Succession Succession
+	// MaxDiscussionRounds and MaxDiscussionMessages bound each fight and loot discussion, zero meaning no limit.
+	MaxDiscussionRounds   uint
+	MaxDiscussionMessages uint
+	// MaxFightRounds is the number of rounds the agents have to slay a level's monster, zero meaning no limit.
+	// What happens once it is reached is decided by StalemateRule.
+	MaxFightRounds uint
+	StalemateRule  StalemateRule
+	// EnragePercentage is how much the monster's attack grows each round past MaxFightRounds under StalemateEnrage.
+	EnragePercentage uint
+	// DamageModel is how the monster's damage is shared between the agents it hits, empty meaning DamageEven.
+	DamageModel DamageModel
+	// StaminaModel is what fighting costs the agents in stamina and how they recover it.
+	StaminaModel StaminaModel
+	// TradeRounds is the number of rounds in each level's trade stage, and TradeRoundLimit the number of rounds
+	// a negotiation stays open for.
+	TradeRounds     uint
+	TradeRoundLimit uint
+	// MonstersPerLevel is the number of monsters on each level, which share the level's monster health and attack.
+	MonstersPerLevel uint
+	// FightHistoryLevels is the number of previous levels whose fight rounds the agents are shown, besides the
+	// rounds of the current level.
+	FightHistoryLevels uint
+	// Monsters is the catalogue each level's monster is drawn from, nil meaning monster.DefaultCatalogue.
+	Monsters monster.Catalogue
+	// DecisionTimeout is how long each call into an agent's strategy may take, zero meaning no limit.
+	DecisionTimeout time.Duration
+	// AgentQuantities overrides AGENT_<NAME>_QUANTITY for the named teams, see AgentQuantity.
+	AgentQuantities map[string]uint
+}
+
+// StalemateRule decides what happens when the agents fail to slay a level's monster within MaxFightRounds.
+type StalemateRule string
+
+const (
+	// StalemateLose loses the game.
+	StalemateLose StalemateRule = "lose"
+	// StalemateRetreat ends the fight and moves on without any loot, as the monster retreats with it.
+	StalemateRetreat StalemateRule = "retreat"
+	// StalemateEnrage carries on fighting, with the monster's attack growing by EnragePercentage every round.
+	StalemateEnrage StalemateRule = "enrage"
+)
+
+// StaminaModel sets the stamina economy of the fight. An agent needs more stamina than the cost of attacking or
+// defending to take that action, and cowers otherwise.
+type StaminaModel struct {
+	// AttackCost and DefendCost are the base costs of attacking and defending, to which ItemWeight times the bonus
+	// of the weapon or shield in use is added, rounded up.
+	AttackCost uint
+	DefendCost uint
+	ItemWeight float32
+	// CowerStamina is the stamina an agent recovers by cowering, and CowerHpPct the health, as a percentage
+	// of the starting health.
+	CowerStamina uint
+	CowerHpPct   uint
+	// RoundRegen and LevelRegen are the stamina every living agent recovers at the end of each fight round
+	// and of each level.
+	RoundRegen uint
+	LevelRegen uint
+}
+
+// RecallRules decide when a confidence vote recalls the leader, and what happens next.
+type RecallRules struct {
+	// Quorum is the percentage of the living agents whose votes must be counted for the vote to stand,
+	// zero meaning any number.
+	Quorum uint
+	// Supermajority is the percentage of the counted votes that must be against the leader to recall it.
+	// Zero leaves it to the overthrow threshold of the leader's manifesto, which the votes against must exceed.
+	Supermajority uint
+	// CountAbstentions counts abstentions as votes that are not against the leader, rather than leaving them out.
+	CountAbstentions bool
+	// Cooldown is the number of levels after a recall before the next confidence vote is held.
+	Cooldown uint
+	// Outcome is what follows a recall, empty meaning RecallElection.
+	Outcome RecallOutcome
+}
+
+// RecallOutcome decides who leads once the leader has been recalled.
+type RecallOutcome string
+
+const (
+	// RecallElection holds a snap election.
+	RecallElection RecallOutcome = "election"
+	// RecallDeputy hands the rest of the term to the deputy named in the leader's manifesto or, failing that,
+	// the runner-up of the election that chose the leader, holding a snap election instead if neither is alive.
+	RecallDeputy RecallOutcome = "deputy"
+)
+
+// Valid reports whether o names a recall outcome.
+func (o RecallOutcome) Valid() bool {
+	switch o {
+	case "", RecallElection, RecallDeputy:
+		return true
+	}
+	return false
+}
+
+// Succession decides who leads once the leader has died, if its manifesto names no living deputy to take over.
+type Succession string
+
+const (
+	// SuccessionRunnerUp hands the rest of the term to the runner-up of the election that chose the leader,
+	// holding a snap election instead if it is not alive.
+	SuccessionRunnerUp Succession = "runner_up"
+	// SuccessionElection holds a snap election once the stage the leader died in is over.
+	SuccessionElection Succession = "election"
+	// SuccessionLeaderless leaves the agents without a leader, or a manifesto, until the next level's election.
+	SuccessionLeaderless Succession = "leaderless"
+)
+
+// Valid reports whether s names a succession rule.
+func (s Succession) Valid() bool {
+	switch s {
+	case "", SuccessionRunnerUp, SuccessionElection, SuccessionLeaderless:
+		return true
+	}
+	return false
+}
+
+// DamageModel decides how the damage the monster deals in a fight round is shared between the agents it hits:
+// those that attacked or defended, or every agent if they all cowered.
+type DamageModel string
+
+const (
+	// DamageEven splits the damage evenly.
+	DamageEven DamageModel = "even"
+	// DamageDefenseWeighted splits the damage in inverse proportion to each agent's total defense.
+	DamageDefenseWeighted DamageModel = "defense_weighted"
+	// DamageFocusFire splits the damage evenly between the quarter of the attackers with the highest total attack,
+	// or every agent hit if none attacked.
+	DamageFocusFire DamageModel = "focus_fire"
+	// DamageRandomSubset splits the damage evenly between a random half of the agents hit.
+	DamageRandomSubset DamageModel = "random_subset"
+	// DamageShieldersFirst has the defending agents absorb the damage, each up to its total defense,
+	// and splits what they cannot absorb evenly between the attackers.
+	DamageShieldersFirst DamageModel = "shielders_first"
+)
+
+// Valid reports whether m names a damage model.
+func (m DamageModel) Valid() bool {
+	switch m {
+	case "", DamageEven, DamageDefenseWeighted, DamageFocusFire, DamageRandomSubset, DamageShieldersFirst:
+		return true
+	}
+	return false
+}
+
+// TieBreak decides which of the candidates tied in an election, or in one of its counts, goes through.
+type TieBreak string
+
+const (
+	// TieBreakRandom draws one of the tied candidates from the game's random source.
+	TieBreakRandom TieBreak = "random"
+	// TieBreakID picks the tied candidate with the lowest ID.
+	TieBreakID TieBreak = "id"
+	// TieBreakIncumbent picks the current leader if it is tied, and draws one of the tied candidates otherwise.
+	TieBreakIncumbent TieBreak = "incumbent"
+)
+
+// Valid reports whether t names a tie-break rule.
+func (t TieBreak) Valid() bool {
+	switch t {
+	case "", TieBreakRandom, TieBreakID, TieBreakIncumbent:
+		return true
+	}
+	return false
 }
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"infra/game/commons"
+	"infra/game/decision"
+)
+
+// Validate checks that a game can be played with c by agents of the named teams, reporting every problem found.
+func (c GameConfig) Validate(teams []string) error {
+	var problems []string
+	problemf := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.NumLevels == 0 {
+		problemf("LEVELS must be at least 1")
+	}
+	if c.ThresholdPercentage <= 0 || c.ThresholdPercentage > 1 {
+		problemf("THRESHOLD_PCT must be in (0, 1], not %v", c.ThresholdPercentage)
+	}
+	if c.StartingHealthPoints == 0 {
+		problemf("STARTING_HP must be at least 1")
+	}
+	switch c.StalemateRule {
+	case StalemateLose, StalemateRetreat, StalemateEnrage:
+	default:
+		problemf("STALEMATE_RULE must be %s, %s or %s, not %q", StalemateLose, StalemateRetreat, StalemateEnrage, c.StalemateRule)
+	}
+
+	if c.VotingStrategy > uint(decision.TwoRoundRunoff) {
+		problemf("VOTING_STRATEGY must be between %d and %d, not %d", decision.SingleChoicePlurality, decision.TwoRoundRunoff, c.VotingStrategy)
+	}
+	if !c.TieBreak.Valid() {
+		problemf("ELECTION_TIE_BREAK must be %s, %s or %s, not %q", TieBreakRandom, TieBreakID, TieBreakIncumbent, c.TieBreak)
+	}
+	if c.Recall.Quorum > 100 || c.Recall.Supermajority > 100 {
+		problemf("RECALL_QUORUM_PCT and RECALL_SUPERMAJORITY_PCT must be at most 100, not %d and %d", c.Recall.Quorum, c.Recall.Supermajority)
+	}
+	if !c.Recall.Outcome.Valid() {
+		problemf("RECALL_OUTCOME must be %s or %s, not %q", RecallElection, RecallDeputy, c.Recall.Outcome)
+	}
+	if !c.Succession.Valid() {
+		problemf("LEADER_SUCCESSION must be %s, %s or %s, not %q", SuccessionRunnerUp, SuccessionElection, SuccessionLeaderless, c.Succession)
+	}
+	if !c.DamageModel.Valid() {
+		problemf("DAMAGE_MODEL must be %s, %s, %s, %s or %s, not %q", DamageEven, DamageDefenseWeighted, DamageFocusFire, DamageRandomSubset, DamageShieldersFirst, c.DamageModel)
+	}
+
+	if c.MonstersPerLevel == 0 {
+		problemf("MONSTERS_PER_LEVEL must be at least 1")
+	}
+	if c.StaminaModel.ItemWeight < 0 {
+		problemf("STAMINA_ITEM_WEIGHT must not be negative, not %v", c.StaminaModel.ItemWeight)
+	}
+
+	known := make(map[string]bool, len(teams))
+	numAgents := uint(0)
+	for _, team := range teams {
+		known[team] = true
+		numAgents += c.AgentQuantity(team)
+	}
+	for _, team := range commons.SortedKeys(c.AgentQuantities) {
+		if !known[team] {
+			problemf("no team named %s to create agents for", team)
+		}
+	}
+	if numAgents == 0 {
+		problemf("the game needs at least one agent")
+	} else if c.VotingPreferences == 0 || c.VotingPreferences > numAgents {
+		problemf("VOTING_PREFERENCES must be between 1 and the %d agents that can stand, not %d", numAgents, c.VotingPreferences)
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid game config: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
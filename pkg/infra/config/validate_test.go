@@ -0,0 +1,90 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"infra/config"
+)
+
+func validConfig() config.GameConfig {
+	return config.GameConfig{
+		NumLevels:            60,
+		StartingHealthPoints: 1000,
+		ThresholdPercentage:  0.6,
+		VotingPreferences:    2,
+		StalemateRule:        config.StalemateLose,
+		MonstersPerLevel:     1,
+		AgentQuantities:      map[string]uint{"RANDOM": 10},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		modify  func(c *config.GameConfig)
+		problem string
+	}{
+		{"valid", func(c *config.GameConfig) {}, ""},
+		{"no levels", func(c *config.GameConfig) { c.NumLevels = 0 }, "LEVELS"},
+		{"zero threshold", func(c *config.GameConfig) { c.ThresholdPercentage = 0 }, "THRESHOLD_PCT"},
+		{"threshold above one", func(c *config.GameConfig) { c.ThresholdPercentage = 1.1 }, "THRESHOLD_PCT"},
+		{"unknown voting strategy", func(c *config.GameConfig) { c.VotingStrategy = 7 }, "VOTING_STRATEGY"},
+		{"unknown tie break", func(c *config.GameConfig) { c.TieBreak = "coin" }, "ELECTION_TIE_BREAK"},
+		{"unknown recall outcome", func(c *config.GameConfig) { c.Recall.Outcome = "coup" }, "RECALL_OUTCOME"},
+		{"quorum over 100%", func(c *config.GameConfig) { c.Recall.Quorum = 101 }, "RECALL_QUORUM_PCT"},
+		{"unknown succession", func(c *config.GameConfig) { c.Succession = "coup" }, "LEADER_SUCCESSION"},
+		{"unknown stalemate rule", func(c *config.GameConfig) { c.StalemateRule = "surrender" }, "STALEMATE_RULE"},
+		{"no monsters", func(c *config.GameConfig) { c.MonstersPerLevel = 0 }, "MONSTERS_PER_LEVEL"},
+		{"negative item weight", func(c *config.GameConfig) { c.StaminaModel.ItemWeight = -1 }, "STAMINA_ITEM_WEIGHT"},
+		{"unknown team", func(c *config.GameConfig) { c.AgentQuantities["TEAM9"] = 1 }, "TEAM9"},
+		{"no agents", func(c *config.GameConfig) { c.AgentQuantities["RANDOM"] = 0 }, "at least one agent"},
+		{"more preferences than agents", func(c *config.GameConfig) { c.VotingPreferences = 11 }, "VOTING_PREFERENCES"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := validConfig()
+			tt.modify(&c)
+			err := c.Validate([]string{"RANDOM"})
+			if tt.problem == "" && err != nil {
+				t.Errorf("Validate() = %v; want nil", err)
+			}
+			if tt.problem != "" && (err == nil || !strings.Contains(err.Error(), tt.problem)) {
+				t.Errorf("Validate() = %v; want an error about %s", err, tt.problem)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	file := `{"Game": {"LEVELS": 20, "THRESHOLD_PCT": 0.5, "STALEMATE_RULE": "retreat"}, "Agents": {"RANDOM": 5}}`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := validConfig()
+	if err := c.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile() = %v", err)
+	}
+	if c.NumLevels != 20 || c.ThresholdPercentage != 0.5 || c.StalemateRule != config.StalemateRetreat || c.AgentQuantity("RANDOM") != 5 {
+		t.Errorf("LoadFile() gave %+v", c)
+	}
+
+	for _, file := range []string{`{"Game": {"LEVLES": 20}}`, `{"Agent": {"RANDOM": 5}}`, `{"Game": {"AGENT_RANDOM_QUANTITY": 5}}`} {
+		if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.LoadFile(path); err == nil {
+			t.Errorf("LoadFile(%s) = nil; want an error", file)
+		}
+	}
+}
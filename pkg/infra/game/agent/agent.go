@@ -5,8 +5,10 @@ import (
 	"infra/game/commons"
 	"infra/game/decision"
 	"infra/game/message"
+	"infra/game/message/proposal"
 	"infra/game/state"
 	"infra/logging"
+	"sync"
 
 	"github.com/benbjohnson/immutable"
 )
@@ -16,62 +18,198 @@ type Agent struct {
 	Strategy
 }
 
+// The Handle methods below are the engine's only way into a strategy. Each call is made within the
+// agent's decision budget (see Supervise); when it overruns or panics, the agent is given the default
+// action noted on the method.
+
+// HandleDonateToHpPool defaults to donating nothing.
 func (a *Agent) HandleDonateToHpPool(agentState state.AgentState) uint {
 	a.BaseAgent.latestState = agentState
 
-	return a.Strategy.DonateToHpPool(*a.BaseAgent)
+	return call(a, "DonateToHpPool", 0, func(baseAgent BaseAgent) uint {
+		return a.Strategy.DonateToHpPool(baseAgent)
+	})
 }
 
-func (a *Agent) HandleUpdateInternalState(agentState state.AgentState, fightResults *commons.ImmutableList[decision.ImmutableFightResult], voteResults *immutable.Map[decision.Intent, uint], logChan chan<- logging.AgentLog) {
+// HandleUpdateInternalState defaults to leaving the strategy's state as it is. Every log the strategy sends
+// before the budget runs out is passed on to logChan, in order; any it sends later are dropped. The agent's
+// delta for the level is available through LevelDelta.
+func (a *Agent) HandleUpdateInternalState(agentState state.AgentState, delta state.Delta, fightResults *commons.ImmutableList[decision.ImmutableFightResult], voteResults *immutable.Map[decision.Intent, uint], logChan chan<- logging.AgentLog) {
 	a.BaseAgent.latestState = agentState
+	a.BaseAgent.levelDelta = delta
+
+	// drain the strategy's logs as it sends them, so that it never blocks on a send, and hold on to them until
+	// the call is over: an overrunning strategy may carry on sending after logChan has been closed
+	var mu sync.Mutex
+	var logs []logging.AgentLog
+	late := false
+	agentLogs := make(chan logging.AgentLog)
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		for agentLog := range agentLogs {
+			mu.Lock()
+			if !late {
+				logs = append(logs, agentLog)
+			}
+			mu.Unlock()
+		}
+	}()
+	do(a, "UpdateInternalState", func(baseAgent BaseAgent) {
+		defer func() {
+			close(agentLogs)
+			<-drained
+		}()
+		a.Strategy.UpdateInternalState(baseAgent, fightResults, voteResults, agentLogs)
+	})
 
-	a.Strategy.UpdateInternalState(*a.BaseAgent, fightResults, voteResults, logChan)
+	mu.Lock()
+	late = true
+	sent := logs
+	mu.Unlock()
+	for _, agentLog := range sent {
+		logChan <- agentLog
+	}
 }
 
+// HandleUpdateWeapon defaults to keeping the weapon in use.
 func (a *Agent) HandleUpdateWeapon(agentState state.AgentState) decision.ItemIdx {
 	a.BaseAgent.latestState = agentState
 
-	return a.Strategy.HandleUpdateWeapon(*a.BaseAgent)
+	// an out of range index leaves the weapon in use unchanged
+	return call(a, "HandleUpdateWeapon", decision.ItemIdx(agentState.Weapons.Len()), func(baseAgent BaseAgent) decision.ItemIdx {
+		return a.Strategy.HandleUpdateWeapon(baseAgent)
+	})
 }
 
+// HandleUpdateShield defaults to keeping the shield in use.
 func (a *Agent) HandleUpdateShield(agentState state.AgentState) decision.ItemIdx {
 	a.BaseAgent.latestState = agentState
-	return a.Strategy.HandleUpdateShield(*a.BaseAgent)
+
+	return call(a, "HandleUpdateShield", decision.ItemIdx(agentState.Shields.Len()), func(baseAgent BaseAgent) decision.ItemIdx {
+		return a.Strategy.HandleUpdateShield(baseAgent)
+	})
 }
 
+// SubmitManifesto defaults to a manifesto claiming no decision power for a single level.
 func (a *Agent) SubmitManifesto(agentState state.AgentState) *decision.Manifesto {
 	a.BaseAgent.latestState = agentState
 
-	return a.Strategy.CreateManifesto(*a.BaseAgent)
+	return call(a, "CreateManifesto", decision.NewManifesto(false, false, 1, 50), func(baseAgent BaseAgent) *decision.Manifesto {
+		return a.Strategy.CreateManifesto(baseAgent)
+	})
 }
 
-// HandleNoConfidenceVote todo: do we need to send the baseAgent here? I.e. is communication necessary at this point?
+// HandleConfidenceDiscussion takes part in the discussion held before a confidence vote until rounds is closed,
+// see discuss. Agents whose strategies are not Pollsters only listen.
+func (a *Agent) HandleConfidenceDiscussion(agentState state.AgentState, rounds <-chan []message.TaggedMessage, reports chan<- RoundReport) {
+	a.BaseAgent.latestState = agentState
+	pollster, ok := a.Strategy.(Pollster)
+	a.discuss(rounds, reports, func(m message.TaggedMessage) {
+		if ok {
+			do(a, "HandleConfidenceMessage", func(baseAgent BaseAgent) {
+				pollster.HandleConfidenceMessage(m, baseAgent)
+			})
+		}
+	})
+}
+
+// HandleNoConfidenceVote defaults to abstaining. It is called once the discussion held before the vote is over.
 func (a *Agent) HandleNoConfidenceVote(agentState state.AgentState) decision.Intent {
 	a.BaseAgent.latestState = agentState
 
-	return a.Strategy.HandleConfidencePoll(*a.BaseAgent)
+	return call(a, "HandleConfidencePoll", decision.Abstain, func(baseAgent BaseAgent) decision.Intent {
+		return a.Strategy.HandleConfidencePoll(baseAgent)
+	})
 }
 
+// HandleElection defaults to an empty ballot.
 func (a *Agent) HandleElection(agentState state.AgentState, params *decision.ElectionParams) decision.Ballot {
 	a.BaseAgent.latestState = agentState
 
-	return a.Strategy.HandleElectionBallot(*a.BaseAgent, params)
+	return call(a, "HandleElectionBallot", decision.Ballot{}, func(baseAgent BaseAgent) decision.Ballot {
+		return a.Strategy.HandleElectionBallot(baseAgent, params)
+	})
+}
+
+// HandleFightAction defaults to cowering.
+func (a *Agent) HandleFightAction(proposedAction decision.FightAction, acceptedProposal message.Proposal[decision.FightAction]) decision.FightAction {
+	return call(a, "FightAction", decision.Cower, func(baseAgent BaseAgent) decision.FightAction {
+		return a.Strategy.FightAction(baseAgent, proposedAction, acceptedProposal)
+	})
 }
 
+// HandleFightActionNoProposal defaults to cowering.
+func (a *Agent) HandleFightActionNoProposal() decision.FightAction {
+	return call(a, "FightActionNoProposal", decision.Cower, func(baseAgent BaseAgent) decision.FightAction {
+		return a.Strategy.FightActionNoProposal(baseAgent)
+	})
+}
+
+// HandleFightTarget defaults to the proposed target, as do strategies that are not Targeters.
+func (a *Agent) HandleFightTarget(action decision.FightAction, proposedTarget decision.MonsterIdx) decision.MonsterIdx {
+	targeter, ok := a.Strategy.(Targeter)
+	if !ok {
+		return proposedTarget
+	}
+	return call(a, "FightTarget", proposedTarget, func(baseAgent BaseAgent) decision.MonsterIdx {
+		return targeter.FightTarget(baseAgent, action, proposedTarget)
+	})
+}
+
+// HandleFightResolution is only called on the leader, and defaults to the proposed actions.
+func (a *Agent) HandleFightResolution(prop commons.ImmutableList[proposal.Rule[decision.FightAction]], proposedActions immutable.Map[commons.ID, decision.FightAction]) immutable.Map[commons.ID, decision.FightAction] {
+	return call(a, "FightResolution", proposedActions, func(baseAgent BaseAgent) immutable.Map[commons.ID, decision.FightAction] {
+		return a.Strategy.FightResolution(baseAgent, prop, proposedActions)
+	})
+}
+
+// HandleLootAllocation is only called on the leader, and defaults to the proposed allocation.
+func (a *Agent) HandleLootAllocation(
+	prop message.Proposal[decision.LootAction],
+	proposedAllocations immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]],
+) immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]] {
+	return call(a, "LootAllocation", proposedAllocations, func(baseAgent BaseAgent) immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]] {
+		return a.Strategy.LootAllocation(baseAgent, prop, proposedAllocations)
+	})
+}
+
+// HandleLootAction defaults to taking nothing.
+func (a *Agent) HandleLootAction(proposedLoot immutable.SortedMap[commons.ItemID, struct{}], acceptedProposal message.Proposal[decision.LootAction]) immutable.SortedMap[commons.ItemID, struct{}] {
+	return call(a, "LootAction", *immutable.NewSortedMap[commons.ItemID, struct{}](nil), func(baseAgent BaseAgent) immutable.SortedMap[commons.ItemID, struct{}] {
+		return a.Strategy.LootAction(baseAgent, proposedLoot, acceptedProposal)
+	})
+}
+
+// HandleLootActionNoProposal defaults to taking nothing.
+func (a *Agent) HandleLootActionNoProposal() immutable.SortedMap[commons.ItemID, struct{}] {
+	return call(a, "LootActionNoProposal", *immutable.NewSortedMap[commons.ItemID, struct{}](nil), func(baseAgent BaseAgent) immutable.SortedMap[commons.ItemID, struct{}] {
+		return a.Strategy.LootActionNoProposal(baseAgent)
+	})
+}
+
+// HandleFight takes part in the fight discussion until rounds is closed, see discuss.
 func (a *Agent) HandleFight(agentState state.AgentState,
 	log immutable.Map[commons.ID, decision.FightAction],
 	votes chan commons.ProposalID,
 	submission chan message.Proposal[decision.FightAction],
-	closure <-chan struct{},
+	rounds <-chan []message.TaggedMessage,
+	reports chan<- RoundReport,
 ) {
 	a.BaseAgent.latestState = agentState
-	for {
-		select {
-		case taggedMessage := <-a.BaseAgent.communication.receipt:
-			a.handleFightRoundMessage(&log, taggedMessage, votes, submission)
-		case <-closure:
-			return
+	a.discuss(rounds, reports, func(m message.TaggedMessage) {
+		a.handleFightRoundMessage(&log, m, votes, submission)
+	})
+}
+
+// discuss handles each round's inbox in order, then reports the messages sent while doing so,
+// which the server delivers at the start of the next round.
+func (a *Agent) discuss(rounds <-chan []message.TaggedMessage, reports chan<- RoundReport, handle func(message.TaggedMessage)) {
+	for inbox := range rounds {
+		for _, m := range inbox {
+			handle(m)
 		}
+		reports <- RoundReport{Agent: a.BaseAgent.ID(), Outbox: a.BaseAgent.communication.takeOutbox()}
 	}
 }
 
@@ -88,28 +226,32 @@ func (a *Agent) handleFightRoundMessage(log *immutable.Map[commons.ID, decision.
 	votes chan commons.ProposalID,
 	submission chan message.Proposal[decision.FightAction],
 ) {
+	// while discussing, a misbehaving agent sends no response, ignores the message or proposal, and abstains
 	switch r := m.Message().(type) {
 	case message.FightRequest:
 		req := *message.NewTaggedRequestMessage[message.FightRequest](m.Sender(), r, m.MID())
-		resp := a.Strategy.HandleFightRequest(req, log)
-		err := a.BaseAgent.SendBlockingMessage(m.Sender(), resp)
-		logging.Log(logging.Error, nil, err.Error())
+		resp := call[message.FightInform](a, "HandleFightRequest", nil, func(BaseAgent) message.FightInform {
+			return a.Strategy.HandleFightRequest(req, log)
+		})
+		a.respond(m.Sender(), resp)
 	case message.FightInform:
 		inf := *message.NewTaggedInformMessage[message.FightInform](m.Sender(), r, m.MID())
-		a.Strategy.HandleFightInformation(inf, *a.BaseAgent, log)
+		do(a, "HandleFightInformation", func(baseAgent BaseAgent) {
+			a.Strategy.HandleFightInformation(inf, baseAgent, log)
+		})
 
 	case message.Proposal[decision.FightAction]:
 		if a.isLeader() {
-			if a.Strategy.HandleFightProposalRequest(r, *a.BaseAgent, log) {
+			if call(a, "HandleFightProposalRequest", false, func(baseAgent BaseAgent) bool {
+				return a.Strategy.HandleFightProposalRequest(r, baseAgent, log)
+			}) {
 				submission <- r
-				iterator := a.BaseAgent.communication.peer.Iterator()
-				for !iterator.Done() {
-					_, value, _ := iterator.Next()
-					value <- m
-				}
+				a.BaseAgent.communication.broadcast(m)
 			}
 		}
-		switch a.Strategy.HandleFightProposal(r, *a.BaseAgent) {
+		switch call(a, "HandleFightProposal", decision.Abstain, func(baseAgent BaseAgent) decision.Intent {
+			return a.Strategy.HandleFightProposal(r, baseAgent)
+		}) {
 		case decision.Positive:
 			votes <- r.ProposalID()
 		default:
@@ -119,18 +261,19 @@ func (a *Agent) handleFightRoundMessage(log *immutable.Map[commons.ID, decision.
 	}
 }
 
-func (a *Agent) HandleLoot(agentState state.AgentState, votes chan commons.ProposalID, submission chan message.Proposal[decision.LootAction], closure chan struct{}, start <-chan message.StartLoot) {
+// HandleLoot takes part in the loot discussion over pool until rounds is closed, see discuss.
+func (a *Agent) HandleLoot(agentState state.AgentState,
+	pool state.LootPool,
+	votes chan commons.ProposalID,
+	submission chan message.Proposal[decision.LootAction],
+	rounds <-chan []message.TaggedMessage,
+	reports chan<- RoundReport,
+) {
 	a.BaseAgent.latestState = agentState
-	for {
-		select {
-		case loot := <-start:
-			a.addLoot(loot.LootPool)
-		case taggedMessage := <-a.BaseAgent.communication.receipt:
-			a.handleLootRoundMessage(taggedMessage, votes, submission)
-		case <-closure:
-			return
-		}
-	}
+	a.addLoot(pool)
+	a.discuss(rounds, reports, func(m message.TaggedMessage) {
+		a.handleLootRoundMessage(m, votes, submission)
+	})
 }
 
 func (a *Agent) handleLootRoundMessage(
@@ -141,24 +284,27 @@ func (a *Agent) handleLootRoundMessage(
 	switch r := m.Message().(type) {
 	case message.LootRequest:
 		req := *message.NewTaggedRequestMessage[message.LootRequest](m.Sender(), r, m.MID())
-		resp := a.Strategy.HandleLootRequest(req)
-		err := a.BaseAgent.SendBlockingMessage(m.Sender(), resp)
-		logging.Log(logging.Error, nil, err.Error())
+		resp := call[message.LootInform](a, "HandleLootRequest", nil, func(baseAgent BaseAgent) message.LootInform {
+			return a.Strategy.HandleLootRequest(req, baseAgent)
+		})
+		a.respond(m.Sender(), resp)
 	case message.LootInform:
 		inf := *message.NewTaggedInformMessage[message.LootInform](m.Sender(), r, m.MID())
-		a.Strategy.HandleLootInformation(inf, *a.BaseAgent)
+		do(a, "HandleLootInformation", func(baseAgent BaseAgent) {
+			a.Strategy.HandleLootInformation(inf, baseAgent)
+		})
 	case message.Proposal[decision.LootAction]:
 		if a.isLeader() {
-			if a.Strategy.HandleLootProposalRequest(r, *a.BaseAgent) {
+			if call(a, "HandleLootProposalRequest", false, func(baseAgent BaseAgent) bool {
+				return a.Strategy.HandleLootProposalRequest(r, baseAgent)
+			}) {
 				submission <- r
-				iterator := a.BaseAgent.communication.peer.Iterator()
-				for !iterator.Done() {
-					_, value, _ := iterator.Next()
-					value <- m
-				}
+				a.BaseAgent.communication.broadcast(m)
 			}
 		}
-		switch a.Strategy.HandleLootProposal(r, *a.BaseAgent) {
+		switch call(a, "HandleLootProposal", decision.Abstain, func(baseAgent BaseAgent) decision.Intent {
+			return a.Strategy.HandleLootProposal(r, baseAgent)
+		}) {
 		case decision.Positive:
 			votes <- r.ProposalID()
 		default:
@@ -168,6 +314,16 @@ func (a *Agent) handleLootRoundMessage(
 	}
 }
 
+// respond sends the strategy's answer to a request, if it gave one.
+func (a *Agent) respond(recipient commons.ID, resp message.Message) {
+	if resp == nil {
+		return
+	}
+	if err := a.BaseAgent.SendBlockingMessage(recipient, resp); err != nil {
+		logging.Log(logging.Error, nil, err.Error())
+	}
+}
+
 func (a *Agent) addLoot(pool state.LootPool) {
 	a.BaseAgent.loot = pool
 }
@@ -185,7 +341,10 @@ func (a *Agent) HandleTrade(
 		case <-closure:
 			return
 		case <-next:
-			tradeMessage := a.Strategy.HandleTradeNegotiation(*a.BaseAgent, info)
+			// a misbehaving agent abstains from trading this round
+			tradeMessage := call[message.TradeMessage](a, "HandleTradeNegotiation", message.TradeAbstain{}, func(baseAgent BaseAgent) message.TradeMessage {
+				return a.Strategy.HandleTradeNegotiation(baseAgent, info)
+			})
 			responseChannel <- tradeMessage
 		}
 	}
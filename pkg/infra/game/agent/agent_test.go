@@ -0,0 +1,91 @@
+package agent_test
+
+import (
+	"testing"
+	"time"
+
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/example"
+	"infra/game/state"
+	"infra/logging"
+
+	"github.com/benbjohnson/immutable"
+)
+
+// chattyAgent sends a log for each of bravery's values, pausing for delay after the first.
+type chattyAgent struct {
+	example.RandomAgent
+	bravery []float32
+	delay   time.Duration
+}
+
+func (c *chattyAgent) UpdateInternalState(a agent.BaseAgent, _ *commons.ImmutableList[decision.ImmutableFightResult], _ *immutable.Map[decision.Intent, uint], log chan<- logging.AgentLog) {
+	for i, bravery := range c.bravery {
+		if i == 1 {
+			time.Sleep(c.delay)
+		}
+		log <- logging.AgentLog{Name: a.Name(), ID: a.ID(), Properties: map[string]float32{"bravery": bravery}}
+	}
+}
+
+func TestUpdateInternalStatePassesOnLogs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		budget time.Duration
+		delay  time.Duration
+		want   []float32
+	}{
+		{"unlimited", 0, 0, []float32{1, 2, 3}},
+		{"within budget", time.Second, 0, []float32{1, 2, 3}},
+		// the logs sent after the strategy overran are dropped
+		{"overrun", 10 * time.Millisecond, 200 * time.Millisecond, []float32{1}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			view := state.View{}
+			a := agent.Agent{
+				BaseAgent: agent.NewBaseAgent(nil, "a", "TEST", &view, commons.NewSource(1)),
+				Strategy:  &chattyAgent{bravery: []float32{1, 2, 3}, delay: tt.delay},
+			}
+			a.Supervise(tt.budget, nil)
+
+			logChan := make(chan logging.AgentLog)
+			go func() {
+				a.HandleUpdateInternalState(state.AgentState{}, state.Delta{}, nil, nil, logChan)
+				close(logChan)
+			}()
+
+			var got []float32
+			timeout := time.After(5 * time.Second)
+			for done := false; !done; {
+				select {
+				case log, ok := <-logChan:
+					if !ok {
+						done = true
+						break
+					}
+					got = append(got, log.Properties["bravery"])
+				case <-timeout:
+					t.Fatalf("HandleUpdateInternalState did not return, got logs %v", got)
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got logs %v; want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got logs %v; want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
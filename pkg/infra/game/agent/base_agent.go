@@ -1,10 +1,12 @@
 package agent
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"infra/game/decision"
 	"infra/game/message/proposal"
+	"math/rand"
 
 	"infra/game/commons"
 	"infra/game/message"
@@ -25,14 +27,36 @@ type BaseAgent struct {
 	id            commons.ID
 	name          string
 	latestState   state.AgentState
+	levelDelta    state.Delta
 	view          *state.View
 	loot          state.LootPool
+	rng           *rand.Rand
+	source        *commons.Source
+	guard         *guard
+	ctx           context.Context
 }
 
 func (ba *BaseAgent) Loot() state.LootPool {
 	return ba.loot
 }
 
+// LevelDelta returns the changes made to the agent's state during the level that has just been played.
+// It is set before UpdateInternalState is called.
+func (ba *BaseAgent) LevelDelta() state.Delta {
+	return ba.levelDelta
+}
+
+// FightRounds returns what happened in each fight round of the current level so far: the actions taken, the damage
+// dealt to each agent and the agents killed.
+func (ba *BaseAgent) FightRounds() []state.RoundRecord {
+	return ba.view.FightRounds()
+}
+
+// FightHistory returns the fight rounds of the current level and of the last few levels before it.
+func (ba *BaseAgent) FightHistory() state.FightHistory {
+	return ba.view.FightHistory()
+}
+
 func (ba *BaseAgent) setCommunication(communication *Communication) {
 	ba.communication = communication
 }
@@ -49,22 +73,32 @@ func (ba *BaseAgent) Name() string {
 	return ba.name
 }
 
-func NewBaseAgent(communication *Communication, id commons.ID, agentName string, ptr *state.View) *BaseAgent {
-	return &BaseAgent{communication: communication, id: id, name: agentName, view: ptr}
+// Rand returns the agent's own random source, derived from the game seed.
+// Strategies should draw from it rather than the global math/rand so that seeded runs are reproducible.
+func (ba *BaseAgent) Rand() *rand.Rand {
+	return ba.rng
 }
 
-func (ba *BaseAgent) BroadcastBlockingMessage(m message.Message) {
-	iterator := ba.communication.peer.Iterator()
-	tm := message.NewTaggedMessage(ba.id, m, uuid.New())
-
-	for !iterator.Done() {
-		_, c, ok := iterator.Next()
-		if ok {
-			c <- *tm
-		}
+// Context is done once the current call into the strategy has overrun its decision budget,
+// after which whatever the strategy returns is ignored in favour of a default action.
+func (ba *BaseAgent) Context() context.Context {
+	if ba.ctx == nil {
+		return context.Background()
 	}
+	return ba.ctx
+}
+
+func NewBaseAgent(communication *Communication, id commons.ID, agentName string, ptr *state.View, source *commons.Source) *BaseAgent {
+	return &BaseAgent{communication: communication, id: id, name: agentName, view: ptr, rng: rand.New(source), source: source}
 }
 
+// BroadcastBlockingMessage sends m to every other agent. Like every message sent during a discussion,
+// it is delivered at the start of the next round.
+func (ba *BaseAgent) BroadcastBlockingMessage(m message.Message) {
+	ba.communication.broadcast(*message.NewTaggedMessage(ba.id, m, uuid.New()))
+}
+
+// SendBlockingMessage sends m to the agent with the given id at the start of the next discussion round.
 func (ba *BaseAgent) SendBlockingMessage(id commons.ID, m message.Message) (e error) {
 	switch m.(type) {
 	case message.Proposal[decision.FightAction]:
@@ -72,32 +106,34 @@ func (ba *BaseAgent) SendBlockingMessage(id commons.ID, m message.Message) (e er
 	case message.Proposal[decision.LootAction]:
 		return communicationError("Illegal attempt to send proposal - use SendLootProposalToLeader() instead")
 	default:
-		channel, ok := ba.communication.peer.Get(id)
-		if ok {
-			channel <- *message.NewTaggedMessage(ba.id, m, uuid.New())
-		} else {
+		if !ba.communication.isPeer(id) {
 			return communicationError(fmt.Sprintf("agent %s not available for messaging", id))
 		}
+		ba.communication.send(id, *message.NewTaggedMessage(ba.id, m, uuid.New()))
 	}
 	return nil
 }
 
 func (ba *BaseAgent) SendFightProposalToLeader(rules commons.ImmutableList[proposal.Rule[decision.FightAction]]) error {
-	channel, ok := ba.communication.peer.Get(ba.view.CurrentLeader())
-	if ok {
-		channel <- *message.NewTaggedMessage(ba.id, *message.NewProposal(rules, ba.ID()), uuid.New())
-		return nil
-	}
-	return communicationError("Leader not available for messaging, dead or bad!")
+	return ba.sendToLeader(*message.NewProposal(commons.NewSeededID(ba.rng), rules, ba.ID()))
+}
+
+// SendTargetedFightProposalToLeader proposes the rules along with the monster the agents should fight.
+func (ba *BaseAgent) SendTargetedFightProposalToLeader(rules commons.ImmutableList[proposal.Rule[decision.FightAction]], target decision.MonsterIdx) error {
+	return ba.sendToLeader(*message.NewTargetedProposal(commons.NewSeededID(ba.rng), rules, target, ba.ID()))
 }
 
 func (ba *BaseAgent) SendLootProposalToLeader(rules commons.ImmutableList[proposal.Rule[decision.LootAction]]) error {
-	channel, ok := ba.communication.peer.Get(ba.view.CurrentLeader())
-	if ok {
-		channel <- *message.NewTaggedMessage(ba.id, *message.NewProposal(rules, ba.ID()), uuid.New())
-		return nil
+	return ba.sendToLeader(*message.NewProposal(commons.NewSeededID(ba.rng), rules, ba.ID()))
+}
+
+func (ba *BaseAgent) sendToLeader(m message.Message) error {
+	leader := ba.view.CurrentLeader()
+	if !ba.communication.isPeer(leader) {
+		return communicationError("Leader not available for messaging, dead or bad!")
 	}
-	return communicationError("Leader not available for messaging, dead or bad!")
+	ba.communication.send(leader, *message.NewTaggedMessage(ba.id, m, uuid.New()))
+	return nil
 }
 
 func (ba *BaseAgent) Log(lvl logging.Level, fields logging.LogField, msg string) {
@@ -112,3 +148,9 @@ func (ba *BaseAgent) Log(lvl logging.Level, fields logging.LogField, msg string)
 func (ba *BaseAgent) AgentState() state.AgentState {
 	return ba.latestState
 }
+
+// StaminaCost returns the stamina the agent would spend to take the action in the next fight round.
+// It needs more stamina than the cost to attack or defend, and cowers otherwise.
+func (ba *BaseAgent) StaminaCost(action decision.FightAction) uint {
+	return ba.view.StaminaCost(ba.latestState, action)
+}
@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"fmt"
+
+	"infra/game/commons"
+	"infra/game/state"
+)
+
+// Checkpointable is implemented by strategies with internal state that should survive a game being
+// checkpointed and resumed. Strategies that don't implement it start afresh when a game is resumed.
+type Checkpointable interface {
+	// SaveState encodes the strategy's internal state.
+	SaveState() ([]byte, error)
+	// RestoreState is called on a newly constructed strategy with the data saved by SaveState.
+	RestoreState(data []byte) error
+}
+
+// Checkpoint is everything needed to recreate an agent when resuming a game.
+type Checkpoint struct {
+	ID     commons.ID
+	Name   string
+	Source commons.SourceState
+	// Strategy is the strategy's saved state, if it is Checkpointable.
+	Strategy []byte
+}
+
+func (a *Agent) Checkpoint() (Checkpoint, error) {
+	checkpoint := Checkpoint{ID: a.BaseAgent.id, Name: a.BaseAgent.name, Source: a.BaseAgent.source.State()}
+	if strategy, ok := a.Strategy.(Checkpointable); ok {
+		data, err := strategy.SaveState()
+		if err != nil {
+			return Checkpoint{}, fmt.Errorf("saving the state of agent %s: %w", a.BaseAgent.id, err)
+		}
+		checkpoint.Strategy = data
+	}
+	return checkpoint, nil
+}
+
+// RestoreAgent recreates a checkpointed agent around a newly constructed strategy.
+func RestoreAgent(checkpoint Checkpoint, strategy Strategy, ptr *state.View) (Agent, error) {
+	if restorable, ok := strategy.(Checkpointable); ok && checkpoint.Strategy != nil {
+		if err := restorable.RestoreState(checkpoint.Strategy); err != nil {
+			return Agent{}, fmt.Errorf("restoring the state of agent %s: %w", checkpoint.ID, err)
+		}
+	}
+	return Agent{
+		BaseAgent: NewBaseAgent(nil, checkpoint.ID, checkpoint.Name, ptr, commons.RestoreSource(checkpoint.Source)),
+		Strategy:  strategy,
+	}, nil
+}
@@ -1,17 +1,62 @@
 package agent
 
 import (
+	"sync"
+
 	"infra/game/commons"
 	"infra/game/message"
 
-	"github.com/benbjohnson/immutable"
+	"golang.org/x/exp/slices"
 )
 
+// Envelope is a message waiting to be delivered to Recipient.
+type Envelope struct {
+	Recipient commons.ID
+	Message   message.TaggedMessage
+}
+
+// RoundReport is sent by an agent once it has handled every message of a discussion round.
+// Outbox holds the messages it sent meanwhile, in the order they were sent.
+type RoundReport struct {
+	Agent  commons.ID
+	Outbox []Envelope
+}
+
+// Communication holds who an agent can message and the messages it has sent but the server has
+// not yet delivered. Messages are only delivered between discussion rounds, so sending never blocks.
 type Communication struct {
-	receipt <-chan message.TaggedMessage
-	peer    immutable.Map[commons.ID, chan<- message.TaggedMessage]
+	peers []commons.ID
+	// mu guards outbox against a strategy call that overran its budget and is still sending in the background
+	mu     sync.Mutex
+	outbox []Envelope
+}
+
+// NewCommunication connects an agent to peers, which must be sorted.
+func NewCommunication(peers []commons.ID) *Communication {
+	return &Communication{peers: peers}
+}
+
+func (c *Communication) isPeer(id commons.ID) bool {
+	_, ok := slices.BinarySearch(c.peers, id)
+	return ok
+}
+
+func (c *Communication) send(recipient commons.ID, m message.TaggedMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.outbox = append(c.outbox, Envelope{Recipient: recipient, Message: m})
+}
+
+func (c *Communication) broadcast(m message.TaggedMessage) {
+	for _, peer := range c.peers {
+		c.send(peer, m)
+	}
 }
 
-func NewCommunication(receipt <-chan message.TaggedMessage, peer immutable.Map[commons.ID, chan<- message.TaggedMessage]) *Communication {
-	return &Communication{receipt: receipt, peer: peer}
+func (c *Communication) takeOutbox() []Envelope {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	outbox := c.outbox
+	c.outbox = nil
+	return outbox
 }
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"infra/game/decision"
+	"infra/game/message"
 )
 
 type Election interface {
@@ -9,3 +10,11 @@ type Election interface {
 	HandleConfidencePoll(baseAgent BaseAgent) decision.Intent
 	HandleElectionBallot(baseAgent BaseAgent, params *decision.ElectionParams) decision.Ballot
 }
+
+// Pollster is implemented by strategies that talk to the other agents before a confidence vote, e.g. by
+// broadcasting a message.ConfidenceStance. Agents whose strategies don't implement it only vote.
+type Pollster interface {
+	// HandleConfidenceMessage is called with each message the agent receives while the poll is open, starting
+	// with a message.StartConfidencePoll from the server. Messages sent meanwhile are delivered in the next round.
+	HandleConfidenceMessage(m message.TaggedMessage, baseAgent BaseAgent)
+}
@@ -19,3 +19,12 @@ type Fight interface {
 	FightActionNoProposal(baseAgent BaseAgent) decision.FightAction
 	FightAction(baseAgent BaseAgent, proposedAction decision.FightAction, acceptedProposal message.Proposal[decision.FightAction]) decision.FightAction
 }
+
+// Targeter is implemented by strategies that choose which monster to fight on levels with several monsters.
+// Agents whose strategies don't implement it fight the monster proposed to them.
+type Targeter interface {
+	// FightTarget is called once the agent has chosen to attack or defend, with the monster the winning fight
+	// proposal names, or the first monster still alive if it names none. Choosing a slain monster, or one that
+	// doesn't exist, fights the first monster still alive instead.
+	FightTarget(baseAgent BaseAgent, action decision.FightAction, proposedTarget decision.MonsterIdx) decision.MonsterIdx
+}
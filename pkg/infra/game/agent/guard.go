@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"infra/game/commons"
+)
+
+// Monitor is told when an agent's strategy misbehaves at the boundary with the engine.
+type Monitor interface {
+	// Timeout records that the agent overran its decision budget in the named call and was given the default action.
+	Timeout(agentID commons.ID, agentName string, call string)
+	// Panic records that the named call panicked with value, and the agent was given the default action.
+	Panic(agentID commons.ID, agentName string, call string, value any, stack []byte)
+}
+
+type guard struct {
+	budget  time.Duration
+	monitor Monitor
+}
+
+// Supervise limits each of the agent's strategy calls to budget, reporting overruns and panics to monitor.
+// A zero budget lets calls take as long as they like.
+func (a *Agent) Supervise(budget time.Duration, monitor Monitor) {
+	a.BaseAgent.guard = &guard{budget: budget, monitor: monitor}
+}
+
+// call invokes f, a call named name into the agent's strategy, within the agent's decision budget.
+// If f panics or has not returned in time, fallback is used instead and the misbehaviour reported.
+// Go cannot stop f, so an overrunning call carries on in the background and its result is thrown
+// away; strategies that may take a while should watch BaseAgent.Context and give up once it is done.
+func call[T any](a *Agent, name string, fallback T, f func(baseAgent BaseAgent) T) T {
+	g := a.BaseAgent.guard
+	var o outcome[T]
+	if g == nil || g.budget == 0 {
+		o = run(f, *a.BaseAgent)
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), g.budget)
+		defer cancel()
+		baseAgent := *a.BaseAgent
+		baseAgent.ctx = ctx
+
+		result := make(chan outcome[T], 1)
+		go func() {
+			result <- run(f, baseAgent)
+		}()
+
+		select {
+		case o = <-result:
+		case <-ctx.Done():
+			if g.monitor != nil {
+				g.monitor.Timeout(a.BaseAgent.ID(), a.BaseAgent.Name(), name)
+			}
+			return fallback
+		}
+	}
+
+	if o.panicked {
+		if g != nil && g.monitor != nil {
+			g.monitor.Panic(a.BaseAgent.ID(), a.BaseAgent.Name(), name, o.value, o.stack)
+		}
+		return fallback
+	}
+	return o.result
+}
+
+// outcome is the result of a strategy call, or the panic it raised instead.
+type outcome[T any] struct {
+	result   T
+	panicked bool
+	value    any
+	stack    []byte
+}
+
+func run[T any](f func(baseAgent BaseAgent) T, baseAgent BaseAgent) (o outcome[T]) {
+	defer func() {
+		if o.panicked {
+			o.value = recover()
+			o.stack = debug.Stack()
+		}
+	}()
+	// assume a panic until f returns, so that even panic(nil) is caught
+	o.panicked = true
+	o.result = f(baseAgent)
+	o.panicked = false
+	return o
+}
+
+// do is call for strategy methods without a result.
+func do(a *Agent, name string, f func(baseAgent BaseAgent)) {
+	call(a, name, struct{}{}, func(baseAgent BaseAgent) struct{} {
+		f(baseAgent)
+		return struct{}{}
+	})
+}
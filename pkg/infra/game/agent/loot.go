@@ -10,7 +10,7 @@ import (
 
 type Loot interface {
 	HandleLootInformation(m message.TaggedInformMessage[message.LootInform], baseAgent BaseAgent)
-	HandleLootRequest(m message.TaggedRequestMessage[message.LootRequest]) message.LootInform
+	HandleLootRequest(m message.TaggedRequestMessage[message.LootRequest], baseAgent BaseAgent) message.LootInform
 	HandleLootProposal(r message.Proposal[decision.LootAction], baseAgent BaseAgent) decision.Intent
 	HandleLootProposalRequest(proposal message.Proposal[decision.LootAction], baseAgent BaseAgent) bool
 	LootAllocation(
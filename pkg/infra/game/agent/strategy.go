@@ -19,5 +19,14 @@ type Strategy interface {
 	// HandleUpdateShield return the index of the shield you want to use in AgentState.Shields
 	HandleUpdateShield(baseAgent BaseAgent) decision.ItemIdx
 
+	// UpdateInternalState is called at the end of each level. The strategy may send any number of logs on logChan,
+	// but must not keep it once it returns.
 	UpdateInternalState(baseAgent BaseAgent, fightResult *commons.ImmutableList[decision.ImmutableFightResult], voteResult *immutable.Map[decision.Intent, uint], logChan chan<- logging.AgentLog)
 }
+
+// Initialisable is implemented by strategies that set up internal state when their agent is created, e.g. by drawing
+// from the agent's seeded random source, which isn't available to the strategy's constructor. Init isn't called on
+// strategies restored from a checkpoint, which get their state back from Checkpointable.RestoreState instead.
+type Initialisable interface {
+	Init(baseAgent BaseAgent)
+}
@@ -1,10 +1,15 @@
 package commons
 
 import (
+	"encoding/binary"
 	"fmt"
+	"math/rand"
 
 	"github.com/benbjohnson/immutable"
+	"github.com/google/uuid"
 	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 func SaturatingSub(x uint, y uint) uint {
@@ -39,6 +44,34 @@ func ImmutableMapKeys[K constraints.Ordered, V any](p immutable.Map[K, V]) []K {
 	return keys
 }
 
+// SortedKeys returns the keys of m in ascending order, giving a stable iteration order where
+// the outcome must not depend on Go's randomised map ordering.
+func SortedKeys[K constraints.Ordered, V any](m map[K]V) []K {
+	keys := maps.Keys(m)
+	slices.Sort(keys)
+	return keys
+}
+
+// NewSeededID returns a UUID drawn from rng, so that identifiers are reproducible for a given seed.
+func NewSeededID(rng *rand.Rand) string {
+	return uuid.Must(uuid.NewRandomFromReader(wholeReader{rng})).String()
+}
+
+// wholeReader reads from rng in whole 64-bit draws. Unlike rand.Rand.Read it keeps no leftover bytes
+// between reads, so the position of rng is fully described by its Source.
+type wholeReader struct {
+	rng *rand.Rand
+}
+
+func (r wholeReader) Read(p []byte) (int, error) {
+	for i := 0; i < len(p); i += 8 {
+		var chunk [8]byte
+		binary.LittleEndian.PutUint64(chunk[:], r.rng.Uint64())
+		copy(p[i:], chunk[:])
+	}
+	return len(p), nil
+}
+
 func MapToImmutable[K constraints.Ordered, V any](m map[K]V) immutable.Map[K, V] {
 	builder := immutable.NewMapBuilder[K, V](nil)
 
@@ -49,6 +82,16 @@ func MapToImmutable[K constraints.Ordered, V any](m map[K]V) immutable.Map[K, V]
 	return *builder.Map()
 }
 
+func ImmutableToMap[K constraints.Ordered, V any](m immutable.Map[K, V]) map[K]V {
+	result := make(map[K]V, m.Len())
+	iterator := m.Iterator()
+	for !iterator.Done() {
+		k, v, _ := iterator.Next()
+		result[k] = v
+	}
+	return result
+}
+
 func ListToImmutableList[I comparable](l []I) immutable.List[I] {
 	v := immutable.NewListBuilder[I]()
 
@@ -1,6 +1,7 @@
 package commons_test
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/benbjohnson/immutable"
@@ -82,3 +83,22 @@ func testEq[Type comparable](a, b []Type) bool {
 	}
 	return true
 }
+
+func TestRestoreSource(t *testing.T) {
+	t.Parallel()
+
+	source := commons.NewSource(42)
+	rng := rand.New(source)
+	for i := 0; i < 100; i++ {
+		rng.Intn(10)
+		rng.Float64()
+		rng.Uint64()
+	}
+
+	restored := rand.New(commons.RestoreSource(source.State()))
+	for i := 0; i < 10; i++ {
+		if want, got := rng.Int63(), restored.Int63(); got != want {
+			t.Fatalf("draw %d after restoring = %d; want %d", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package commons
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Source is a seeded math/rand source that counts the values drawn from it, so that its position can be
+// saved in a checkpoint and restored later by replaying the same number of draws from the same seed.
+type Source struct {
+	seed  int64
+	draws uint64
+	src   rand.Source64
+}
+
+// SourceState is the position of a Source: its seed and how many values have been drawn since.
+type SourceState struct {
+	Seed  int64
+	Draws uint64
+}
+
+func NewSource(seed int64) *Source {
+	return &Source{seed: seed, src: rand.NewSource(seed).(rand.Source64)}
+}
+
+// RestoreSource returns a source positioned where the saved one was.
+func RestoreSource(state SourceState) *Source {
+	s := NewSource(state.Seed)
+	_ = s.AdvanceTo(state.Draws)
+	return s
+}
+
+// AdvanceTo draws from the source until draws values have been drawn in total.
+// It fails if more than that have been drawn already.
+func (s *Source) AdvanceTo(draws uint64) error {
+	if s.draws > draws {
+		return fmt.Errorf("source is at draw %d, past draw %d", s.draws, draws)
+	}
+	for s.draws < draws {
+		s.Uint64()
+	}
+	return nil
+}
+
+func (s *Source) State() SourceState {
+	return SourceState{Seed: s.seed, Draws: s.draws}
+}
+
+func (s *Source) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *Source) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+func (s *Source) Seed(seed int64) {
+	s.seed = seed
+	s.draws = 0
+	s.src.Seed(seed)
+}
@@ -1,6 +1,8 @@
 package decision
 
 import (
+	"encoding/json"
+
 	"infra/game/commons"
 
 	"github.com/benbjohnson/immutable"
@@ -17,6 +19,8 @@ type Manifesto struct {
 	lootDecisionPower  bool
 	termLength         uint
 	overthrowThreshold uint
+	policies           Policies
+	deputy             commons.ID
 }
 
 func (m Manifesto) FightDecisionPower() bool {
@@ -35,6 +39,28 @@ func (m Manifesto) OverthrowThreshold() uint {
 	return m.overthrowThreshold
 }
 
+// Policies are the commitments the leader makes for its term.
+func (m Manifesto) Policies() Policies {
+	return m.policies
+}
+
+// WithPolicies returns a copy of the manifesto that commits to policies.
+func (m Manifesto) WithPolicies(policies Policies) *Manifesto {
+	m.policies = policies.capped()
+	return &m
+}
+
+// Deputy is the agent the leader names to take over if it dies during its term, empty if it names none.
+func (m Manifesto) Deputy() commons.ID {
+	return m.deputy
+}
+
+// WithDeputy returns a copy of the manifesto that names deputy to take over if the leader dies.
+func (m Manifesto) WithDeputy(deputy commons.ID) *Manifesto {
+	m.deputy = deputy
+	return &m
+}
+
 func NewManifesto(fightDecisionPower bool, lootDecisionPower bool, termLength uint, overthrowThreshold uint) *Manifesto {
 	return &Manifesto{
 		fightDecisionPower: fightDecisionPower,
@@ -44,10 +70,41 @@ func NewManifesto(fightDecisionPower bool, lootDecisionPower bool, termLength ui
 	}
 }
 
+type manifestoJSON struct {
+	FightDecisionPower bool
+	LootDecisionPower  bool
+	TermLength         uint
+	OverthrowThreshold uint
+	Policies           Policies
+	Deputy             commons.ID `json:",omitempty"`
+}
+
+// MarshalJSON lets the leader's manifesto be saved in game checkpoints.
+func (m Manifesto) MarshalJSON() ([]byte, error) {
+	return json.Marshal(manifestoJSON{
+		FightDecisionPower: m.fightDecisionPower,
+		LootDecisionPower:  m.lootDecisionPower,
+		TermLength:         m.termLength,
+		OverthrowThreshold: m.overthrowThreshold,
+		Policies:           m.policies,
+		Deputy:             m.deputy,
+	})
+}
+
+func (m *Manifesto) UnmarshalJSON(data []byte) error {
+	var manifesto manifestoJSON
+	if err := json.Unmarshal(data, &manifesto); err != nil {
+		return err
+	}
+	*m = *NewManifesto(manifesto.FightDecisionPower, manifesto.LootDecisionPower, manifesto.TermLength, manifesto.OverthrowThreshold).WithPolicies(manifesto.Policies).WithDeputy(manifesto.Deputy)
+	return nil
+}
+
 type ElectionParams struct {
 	candidateList       *immutable.Map[commons.ID, Manifesto]
 	strategy            VotingStrategy
 	numberOfPreferences uint
+	runoff              bool
 }
 
 func (e ElectionParams) CandidateList() *immutable.Map[commons.ID, Manifesto] {
@@ -62,6 +119,13 @@ func NewElectionParams(candidateList map[commons.ID]Manifesto, strategy VotingSt
 	return &ElectionParams{candidateList: builder.Map(), strategy: strategy, numberOfPreferences: numberOfPreferences}
 }
 
+// NewRunoffParams returns the params of the second round of a TwoRoundRunoff, between the finalists.
+func NewRunoffParams(finalists map[commons.ID]Manifesto, numberOfPreferences uint) *ElectionParams {
+	params := NewElectionParams(finalists, TwoRoundRunoff, numberOfPreferences)
+	params.runoff = true
+	return params
+}
+
 func (e ElectionParams) Strategy() VotingStrategy {
 	return e.strategy
 }
@@ -70,6 +134,12 @@ func (e ElectionParams) NumberOfPreferences() uint {
 	return e.numberOfPreferences
 }
 
+// Runoff reports whether the ballot is the second round of a TwoRoundRunoff, between the two candidates left
+// in CandidateList.
+func (e ElectionParams) Runoff() bool {
+	return e.runoff
+}
+
 // Intent is used for polling.
 // Positive can mean true/agree/have confidence
 // Negative can mean false/disagree/don't have confidence
@@ -87,11 +157,23 @@ const (
 // e.g. 1 candidate in choose-one voting and >1 candidates in ranked voting.
 type Ballot []commons.ID
 
+// VotingStrategy is the rule used to count the ballots of an election, set by VOTING_STRATEGY.
 type VotingStrategy uint
 
 const (
 	SingleChoicePlurality = iota
 	BordaCount
+	// InstantRunoff eliminates the candidate with the fewest first preferences until one has a majority.
+	InstantRunoff
+	// Approval elects the candidate named on the most ballots.
+	Approval
+	// Copeland elects the candidate that wins the most head-to-head contests against the others.
+	Copeland
+	// Schulze elects the candidate with the strongest paths of head-to-head wins over the others.
+	Schulze
+	// TwoRoundRunoff elects a candidate with a majority of first preferences, or else asks the agents for
+	// a second ballot between the top two.
+	TwoRoundRunoff
 )
 
 type HpPoolDonation struct {
@@ -7,3 +7,19 @@ const (
 	Cower
 	Attack
 )
+
+func (a FightAction) String() string {
+	switch a {
+	case Defend:
+		return "Defend"
+	case Cower:
+		return "Cower"
+	case Attack:
+		return "Attack"
+	default:
+		return "Unknown"
+	}
+}
+
+// MonsterIdx is the position of a monster among the monsters of a level, which keep their positions as they die.
+type MonsterIdx uint
@@ -8,3 +8,18 @@ const (
 	HealthPotion
 	StaminaPotion
 )
+
+func (a LootAction) String() string {
+	switch a {
+	case Shield:
+		return "Shield"
+	case Weapon:
+		return "Weapon"
+	case HealthPotion:
+		return "HealthPotion"
+	case StaminaPotion:
+		return "StaminaPotion"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,117 @@
+package decision
+
+import "infra/game/commons"
+
+// Policies are the commitments a manifesto makes for the leader's term, which the engine enforces.
+// The zero value commits to nothing. Percentages are capped at 100.
+type Policies struct {
+	// HpPoolDonation is the percentage of its health every agent must give to the HP pool at the end of a level
+	HpPoolDonation uint
+	// TradeTax is the percentage of the value of an item received in a trade that the agent receiving it pays
+	// into the HP pool, in health
+	TradeTax uint
+	// LootPriority is the order in which the agents are served when the loot is handed out
+	LootPriority LootPriority
+	// CowerCap is the largest percentage of the living agents that may cower in a fight round; 0 sets no cap
+	CowerCap uint
+	// DefectorPunishment is what happens to the agents that defected during a level, and DefectorFine the
+	// percentage of their health they pay into the HP pool when they are fined
+	DefectorPunishment DefectorPunishment
+	DefectorFine       uint
+}
+
+// LootPriority is the order a manifesto's policies serve the agents in when the loot is handed out.
+type LootPriority uint
+
+const (
+	// NoLootPriority serves the agents in ID order, and gives an item several agents want to one of them at random.
+	NoLootPriority LootPriority = iota
+	LowestHealthFirst
+	HighestHealthFirst
+	LowestStaminaFirst
+)
+
+func (p LootPriority) String() string {
+	switch p {
+	case NoLootPriority:
+		return "None"
+	case LowestHealthFirst:
+		return "LowestHealthFirst"
+	case HighestHealthFirst:
+		return "HighestHealthFirst"
+	case LowestStaminaFirst:
+		return "LowestStaminaFirst"
+	default:
+		return "Unknown"
+	}
+}
+
+// DefectorPunishment is what a manifesto's policies do to the agents that defected during a level.
+type DefectorPunishment uint
+
+const (
+	NoPunishment DefectorPunishment = iota
+	// ForfeitLoot gives the level's defectors none of its loot.
+	ForfeitLoot
+	// FineDefectors takes DefectorFine percent of the level's defectors' health into the HP pool.
+	FineDefectors
+)
+
+func (p DefectorPunishment) String() string {
+	switch p {
+	case NoPunishment:
+		return "None"
+	case ForfeitLoot:
+		return "ForfeitLoot"
+	case FineDefectors:
+		return "Fine"
+	default:
+		return "Unknown"
+	}
+}
+
+// Policy names one of the clauses of a manifesto's Policies.
+type Policy string
+
+const (
+	HpPoolDonationPolicy     Policy = "hp_pool_donation"
+	TradeTaxPolicy           Policy = "trade_tax"
+	LootPriorityPolicy       Policy = "loot_priority"
+	CowerCapPolicy           Policy = "cower_cap"
+	DefectorPunishmentPolicy Policy = "defector_punishment"
+)
+
+// Breach is a decision of the leader that went against the policies of its manifesto.
+type Breach struct {
+	Policy Policy
+	// Agents are the agents the decision concerned, e.g. those it let cower or gave loot to
+	Agents []commons.ID `json:",omitempty"`
+}
+
+// capped returns the policies with every percentage at most 100.
+func (p Policies) capped() Policies {
+	percent := func(value uint) uint {
+		if value > 100 {
+			return 100
+		}
+		return value
+	}
+	p.HpPoolDonation = percent(p.HpPoolDonation)
+	p.TradeTax = percent(p.TradeTax)
+	p.CowerCap = percent(p.CowerCap)
+	p.DefectorFine = percent(p.DefectorFine)
+	return p
+}
+
+// CowerLimit returns how many of living agents may cower in a fight round, and false if any number may.
+func (p Policies) CowerLimit(living int) (int, bool) {
+	if p.CowerCap == 0 {
+		return 0, false
+	}
+	return living * int(p.CowerCap) / 100, true
+}
+
+// Share returns the given percentage of amount, rounded down.
+func Share(amount uint, percent uint) uint {
+	return amount * percent / 100
+}
@@ -13,6 +13,26 @@ type FightResult struct {
 	CoweringAgents  []commons.ID
 	AttackSum       uint
 	ShieldSum       uint
+	// Targets is the monster each agent that attacks or defends fights.
+	// AttackSums and ShieldSums are the total attack and defense aimed at each monster.
+	Targets    map[commons.ID]MonsterIdx
+	AttackSums []uint
+	ShieldSums []uint
+}
+
+// Fighting returns the agents that attacked and that defended against the monster.
+func (r *FightResult) Fighting(target MonsterIdx) (attacking []commons.ID, shielding []commons.ID) {
+	for _, id := range r.AttackingAgents {
+		if r.Targets[id] == target {
+			attacking = append(attacking, id)
+		}
+	}
+	for _, id := range r.ShieldingAgents {
+		if r.Targets[id] == target {
+			shielding = append(shielding, id)
+		}
+	}
+	return attacking, shielding
 }
 
 type ImmutableFightResult struct {
@@ -22,6 +42,7 @@ type ImmutableFightResult struct {
 	coweringAgents  immutable.List[commons.ID]
 	attackSum       uint
 	shieldSum       uint
+	targets         immutable.Map[commons.ID, MonsterIdx]
 	round           uint
 }
 
@@ -49,6 +70,11 @@ func (ifr *ImmutableFightResult) ShieldSum() uint {
 	return ifr.shieldSum
 }
 
+// Targets is the monster each agent that attacked or defended fought.
+func (ifr *ImmutableFightResult) Targets() immutable.Map[commons.ID, MonsterIdx] {
+	return ifr.targets
+}
+
 func (ifr *ImmutableFightResult) Round() uint {
 	return ifr.round
 }
@@ -61,6 +87,7 @@ func NewImmutableFightResult(fightResult FightResult, round uint) *ImmutableFigh
 		coweringAgents:  commons.ListToImmutableList(fightResult.CoweringAgents),
 		attackSum:       fightResult.AttackSum,
 		shieldSum:       fightResult.ShieldSum,
+		targets:         commons.MapToImmutable(fightResult.Targets),
 		round:           round,
 	}
 }
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+
+	"infra/config"
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/state"
+	"infra/logging"
+)
+
+// checkpoint is a game saved between two levels.
+type checkpoint struct {
+	Config   config.GameConfig
+	State    state.State
+	TermLeft uint
+	Source   commons.SourceState
+	Agents   []agent.Checkpoint
+	Log      logging.GameLog
+}
+
+// Checkpoint writes everything needed to resume the game from the next level: its state, the position
+// of every random source, the state of Checkpointable strategies and the log so far.
+// It must only be called between levels.
+func (g *Game) Checkpoint(w io.Writer) error {
+	cp := checkpoint{
+		Config:   g.config,
+		State:    *g.state,
+		TermLeft: g.termLeft,
+		Source:   g.source.State(),
+		Agents:   make([]agent.Checkpoint, 0, len(g.agents)),
+		Log:      g.logger.log,
+	}
+	for _, id := range commons.SortedKeys(g.agents) {
+		a := g.agents[id]
+		agentCheckpoint, err := a.Checkpoint()
+		if err != nil {
+			return err
+		}
+		cp.Agents = append(cp.Agents, agentCheckpoint)
+	}
+	return json.NewEncoder(w).Encode(cp)
+}
+
+// Resume recreates a checkpointed game, with the strategies for each team name constructed afresh
+// and restored from the checkpoint where they are Checkpointable.
+func Resume(r io.Reader, strategies map[commons.ID]func() agent.Strategy) (*Game, error) {
+	var cp checkpoint
+	if err := json.NewDecoder(r).Decode(&cp); err != nil {
+		return nil, fmt.Errorf("reading checkpoint: %w", err)
+	}
+
+	source := commons.RestoreSource(cp.Source)
+	g := &Game{
+		config:   cp.Config,
+		state:    &cp.State,
+		agents:   make(map[commons.ID]agent.Agent, len(cp.Agents)),
+		view:     &state.View{},
+		rng:      rand.New(source),
+		source:   source,
+		termLeft: cp.TermLeft,
+		logger:   &gameLogger{log: cp.Log},
+	}
+	g.Observe(g.logger)
+	for _, agentCheckpoint := range cp.Agents {
+		strategy, ok := strategies[agentCheckpoint.Name]
+		if !ok {
+			return nil, fmt.Errorf("no strategy for team %s of checkpointed agent %s", agentCheckpoint.Name, agentCheckpoint.ID)
+		}
+		a, err := agent.RestoreAgent(agentCheckpoint, strategy(), g.view)
+		if err != nil {
+			return nil, err
+		}
+		a.Supervise(g.config.DecisionTimeout, g)
+		g.agents[agentCheckpoint.ID] = a
+	}
+	g.connectAgents()
+	g.updateView()
+
+	return g, nil
+}
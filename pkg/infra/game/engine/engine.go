@@ -0,0 +1,377 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+
+	"infra/config"
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/decision"
+	gamemath "infra/game/math"
+	"infra/game/message"
+	"infra/game/stage/confidence"
+	"infra/game/stage/discussion"
+	"infra/game/stage/fight"
+	"infra/game/stage/hppool"
+	"infra/game/stage/loot"
+	"infra/game/stage/trade"
+	"infra/game/stages"
+	"infra/game/state"
+	"infra/logging"
+
+	"github.com/benbjohnson/immutable"
+)
+
+// Game is a single, self-contained run of the simulation.
+// Several games may be run side by side in one process as long as each has its own strategy instances.
+type Game struct {
+	config   config.GameConfig
+	state    *state.State
+	agents   map[commons.ID]agent.Agent
+	view     *state.View
+	rng      *rand.Rand
+	source   *commons.Source
+	termLeft uint
+	// events is the event log being recorded, if any, and replay the one being replayed
+	events *eventLog
+	replay *replayer
+	// observers are told what happens in the game, starting with logger, which builds the game log
+	observers []Observer
+	logger    *gameLogger
+	// logMu guards the game log against agents reporting timeouts and panics from their own goroutines
+	logMu    sync.Mutex
+	outcome  logging.Outcome
+	finished bool
+}
+
+// NewGame instantiates the agents described by strategies and sets up the first level.
+// InitialNumAgents is filled in from the number of agents created.
+func NewGame(gameConfig config.GameConfig, strategies map[commons.ID]func() agent.Strategy) *Game {
+	source := commons.NewSource(gameConfig.Seed)
+	g := &Game{
+		config: gameConfig,
+		view:   &state.View{},
+		rng:    rand.New(source),
+		source: source,
+		logger: &gameLogger{},
+	}
+	g.Observe(g.logger)
+
+	numAgents, agents, agentStateMap, inventoryMap := stages.InitAgents(strategies, g.config, g.view, g.rng)
+	g.config.InitialNumAgents = numAgents
+	g.agents = agents
+	for _, a := range g.agents {
+		a.Supervise(g.config.DecisionTimeout, g)
+	}
+
+	monsterHealth := gamemath.CalculateMonsterHealth(g.rng, g.config.InitialNumAgents, g.config.Stamina, g.config.NumLevels, 1)
+	monsterAttack := gamemath.CalculateMonsterDamage(g.rng, g.config.InitialNumAgents, g.config.StartingHealthPoints, g.config.Stamina, g.config.ThresholdPercentage, g.config.NumLevels, 1)
+	g.state = &state.State{
+		CurrentLevel: 1,
+		Monsters:     g.spawnMonsters(1, monsterHealth, monsterAttack),
+		AgentState:   agentStateMap,
+		InventoryMap: inventoryMap,
+		Defection:    g.config.Defection,
+		DamageModel:  g.config.DamageModel,
+		StaminaModel: g.config.StaminaModel,
+	}
+	g.connectAgents()
+	g.updateView()
+	g.logger.log.Config = logConfig(g.config, g.Survivors())
+
+	return g
+}
+
+// Config returns the configuration the game is running with.
+func (g *Game) Config() config.GameConfig {
+	return g.config
+}
+
+// Level returns the level currently being played, or the last level played once the game has finished.
+func (g *Game) Level() uint {
+	return g.state.CurrentLevel
+}
+
+// Survivors returns the number of agents still alive for each team name.
+func (g *Game) Survivors() map[string]uint {
+	survivors := make(map[string]uint)
+	for _, a := range g.agents {
+		survivors[a.BaseAgent.Name()]++
+	}
+	return survivors
+}
+
+// Finished reports whether the game has been won or lost.
+func (g *Game) Finished() bool {
+	return g.finished
+}
+
+// Run plays levels until the game is won or lost.
+func (g *Game) Run() (logging.Outcome, logging.GameLog) {
+	for !g.finished {
+		g.StepLevel()
+	}
+	return g.outcome, g.logger.log
+}
+
+// StepLevel plays a single level and reports whether the game has finished.
+// Calling it on a finished game does nothing.
+func (g *Game) StepLevel() bool {
+	if g.finished {
+		return true
+	}
+	if g.state.CurrentLevel > g.config.NumLevels {
+		g.win()
+		return true
+	}
+
+	g.state.TrackDeltas()
+	g.state.StartFightHistory(g.config.FightHistoryLevels)
+	start := LevelStart{
+		Level:         g.state.CurrentLevel,
+		Leader:        g.state.CurrentLeader,
+		HpPool:        g.state.HpPool,
+		MonsterHealth: g.state.MonsterHealth(),
+		MonsterAttack: g.state.MonsterAttack(),
+		Monsters:      commons.ListToImmutableList(g.state.Monsters),
+		Agents:        g.agentStates(),
+	}
+	g.notify(func(o Observer) { o.OnLevelStart(start) })
+	end := LevelEnd{Level: g.state.CurrentLevel}
+
+	// Election Stage
+	_, alive := g.agents[g.state.CurrentLeader]
+	var votes map[decision.Intent]uint
+	if g.termLeft == 0 || !alive {
+		g.termLeft = g.runElection(termEnded)
+	} else if confidence.Due(g.state.CurrentLevel, g.state.LastRecall, g.config.Recall) {
+		g.termLeft, votes = g.runConfidenceVote(g.termLeft)
+	}
+
+	end.SkippedThroughHpPool = g.checkHpPool()
+
+	// allow agents to change the weapon and the shield in use
+	items := g.decide(ItemsEvent, 0, func() Event {
+		return Event{Items: loot.ItemChoices(*g.state, g.agents)}
+	})
+	g.state = loot.ApplyItemChoices(*g.state, items.Items)
+	g.updateView()
+
+	// Battle Rounds
+	fightResultSlice := make([]decision.ImmutableFightResult, 0)
+	// defectors are the agents that defected in the level's fight, whom the leader's policies may punish
+	defectors := make(map[commons.ID]struct{})
+	roundNum := uint(0)
+	retreated := false
+	for g.state.MonsterHealth() != 0 {
+		if g.config.MaxFightRounds != 0 && roundNum >= g.config.MaxFightRounds {
+			if roundNum == g.config.MaxFightRounds {
+				end.Stalemate = string(g.config.StalemateRule)
+				logging.Log(logging.Info, logging.LogField{
+					"currLevel":     g.state.CurrentLevel,
+					"monsterHealth": g.state.MonsterHealth(),
+					"rule":          g.config.StalemateRule,
+				}, "Fight round limit reached")
+			}
+			if g.config.StalemateRule == config.StalemateRetreat {
+				retreated = true
+				break
+			} else if g.config.StalemateRule != config.StalemateEnrage {
+				g.lose(end)
+				return true
+			}
+			g.enrage()
+		}
+		// find out the maximum attack from alive agents
+		maxAttack := uint(0)
+		for _, agentState := range g.state.AgentState {
+			if agentState.Hp > 0 {
+				maxAttack += agentState.TotalAttack()
+			}
+		}
+
+		// the agents are given the actions taken in the level's last round, and the rest of its rounds in their view
+		previousDecisions := immutable.NewMap[commons.ID, decision.FightAction](nil)
+		if rounds := g.state.FightHistory.Current.Rounds; len(rounds) > 0 {
+			previousDecisions = &rounds[len(rounds)-1].Choices
+		}
+		// a silenced round's discussion ends before any agent's message is delivered
+		budget := g.discussionBudget()
+		silenced := g.silenced()
+		if silenced {
+			budget.Rounds = 1
+		}
+		fightDecisions := g.decide(FightEvent, roundNum, func() Event {
+			fightTally := stages.AgentFightDecisions(*g.state, g.agents, *previousDecisions, budget)
+			defection := g.defection()
+			fightActions, breaches := discussion.ResolveFightDiscussion(*g.state, g.agents, g.agents[g.state.CurrentLeader], g.state.LeaderManifesto, fightTally)
+			return Event{Proposals: proposalEvents(fightTally), FightActions: fightActions.Choices, FightTargets: fightActions.Targets, Defectors: g.defectorsSince(defection), Breaches: breaches}
+		})
+		g.markFightDefectors(fightDecisions.Defectors)
+		for _, id := range fightDecisions.Defectors {
+			defectors[id] = struct{}{}
+		}
+		g.reportBreaches(fightDecisions.Breaches)
+		g.capCowering(fightDecisions.FightActions)
+		fightActions := decision.FightResult{Choices: fightDecisions.FightActions, Targets: fightDecisions.FightTargets}
+		monsterAttack := g.state.MonsterAttack()
+		g.state = fight.HandleFightRound(*g.state, g.config.StartingHealthPoints, &fightActions)
+		g.updateView()
+
+		logging.Log(logging.Info, logging.LogField{
+			"currLevel":     g.state.CurrentLevel,
+			"monsterHealth": g.state.MonsterHealth(),
+			"monsterDamage": g.state.MonsterAttack(),
+			"numCoward":     len(fightActions.CoweringAgents),
+			"attackSum":     fightActions.AttackSum,
+			"shieldSum":     fightActions.ShieldSum,
+			"numAgents":     len(g.agents),
+			"maxAttack":     maxAttack,
+			"ability":       g.state.Monsters.Lead().Ability,
+		}, "Battle Summary")
+
+		// NOTE: update the following function when you change AgentState
+		monsterHealth, agentHealth := g.health()
+		teams := g.teams()
+		abilityTriggered := g.damageCalculation(fightActions) || silenced
+		damage := g.damageSince(monsterHealth, agentHealth)
+		g.verify(Event{Kind: DamageEvent, Round: roundNum, Damage: damage})
+		g.recordRound(roundNum, fightActions, damage, agentHealth)
+		round := FightRound{
+			Level:            g.state.CurrentLevel,
+			Round:            roundNum,
+			Actions:          commons.MapToImmutable(fightActions.Choices),
+			AttackingAgents:  commons.ListToImmutableList(fightActions.AttackingAgents),
+			ShieldingAgents:  commons.ListToImmutableList(fightActions.ShieldingAgents),
+			CoweringAgents:   commons.ListToImmutableList(fightActions.CoweringAgents),
+			AttackSum:        fightActions.AttackSum,
+			ShieldSum:        fightActions.ShieldSum,
+			Targets:          commons.MapToImmutable(fightActions.Targets),
+			AttackSums:       commons.ListToImmutableList(fightActions.AttackSums),
+			ShieldSums:       commons.ListToImmutableList(fightActions.ShieldSums),
+			MonsterHealth:    g.state.MonsterHealth(),
+			MonsterAttack:    monsterAttack,
+			Monsters:         commons.ListToImmutableList(g.state.Monsters),
+			Ability:          g.state.Monsters.Lead().Ability,
+			AbilityTriggered: abilityTriggered,
+			AgentsRemaining:  uint(len(g.agents)),
+		}
+		g.notify(func(o Observer) { o.OnFightRound(round) })
+		g.reportDeaths(teams, KilledInFight)
+
+		g.connectAgents()
+		g.succeed()
+
+		if len(g.agents) == 0 || float64(len(g.agents)) < math.Ceil(float64(g.config.ThresholdPercentage)*float64(g.config.InitialNumAgents)) {
+			logging.Log(logging.Info, nil, fmt.Sprintf("Lost on level %d  with %d remaining", g.state.CurrentLevel, len(g.agents)))
+			g.lose(end)
+			return true
+		}
+		g.regenerate(g.state.StaminaModel.RoundRegen)
+		fightResultSlice = append(fightResultSlice, *decision.NewImmutableFightResult(fightActions, roundNum))
+		roundNum++
+	}
+
+	g.fillVacancy()
+
+	// a retreating monster takes its loot with it
+	if !retreated {
+		lootPool := g.generateLootPool(len(g.agents), g.state.CurrentLevel)
+		allocated := LootAllocated{Level: g.state.CurrentLevel}
+		lootDecisions := g.decide(LootEvent, 0, func() Event {
+			lootTally, lootStage := stages.AgentLootDecisions(*g.state, *lootPool, g.agents, g.discussionBudget())
+			allocated.Discussion = lootStage
+			lootActions, breaches := discussion.ResolveLootDiscussion(*g.state, g.agents, lootPool, g.agents[g.state.CurrentLeader], g.state.LeaderManifesto, lootTally, defectors, g.rng)
+			allocated.Discussion.WinningProposal = loot.WinningProposal(lootTally)
+			return Event{Proposals: proposalEvents(lootTally), Allocation: allocationLists(lootActions), Breaches: breaches}
+		})
+		g.reportBreaches(lootDecisions.Breaches)
+		allocated.Allocation = allocationMap(g.forfeitLoot(lootDecisions.Allocation, defectors))
+		g.state = loot.HandleLootAllocation(*g.state, &allocated.Allocation, lootPool)
+		g.notify(func(o Observer) { o.OnLootAllocated(allocated) })
+	}
+
+	var executed []message.TradeNegotiation
+	trades := g.decide(TradeEvent, 0, func() Event {
+		var moves []trade.Move
+		moves, executed = trade.HandleTrade(*g.state, g.agents, g.config.TradeRounds, g.config.TradeRoundLimit)
+		return Event{Trades: moves}
+	})
+	if g.replay != nil {
+		executed = trade.ReplayTrade(*g.state, g.agents, g.config.TradeRounds, g.config.TradeRoundLimit, trades.Trades)
+	}
+	for _, negotiation := range executed {
+		tax := trade.Tax(g.state, negotiation, g.policies().TradeTax)
+		traded := TradeExecuted{Level: g.state.CurrentLevel, Trade: negotiation, Tax: commons.MapToImmutable(tax)}
+		g.notify(func(o Observer) { o.OnTradeExecuted(traded) })
+	}
+
+	g.connectAgents()
+	g.fineDefectors(defectors)
+
+	donated := HpPoolDonation{Level: g.state.CurrentLevel, OldPool: g.state.HpPool}
+	donations := g.decide(DonationEvent, 0, func() Event {
+		return Event{Donations: hppool.Donations(g.agents, g.state)}
+	})
+	teams := g.teams()
+	donated.Donations = commons.MapToImmutable(hppool.Donate(g.agents, g.state, g.mandateDonations(donations.Donations)))
+	donated.NewPool = g.state.HpPool
+	g.notify(func(o Observer) { o.OnHpPoolDonation(donated) })
+	g.reportDeaths(teams, DonatedAllToHp)
+	g.succeed()
+	g.regenerate(g.state.StaminaModel.LevelRegen)
+
+	// TODO: End of level Updates
+	g.termLeft--
+	monsterHealth, monsterAttack := gamemath.GetNextLevelMonsterValues(g.rng, g.config, g.state.CurrentLevel+1)
+	g.state.Monsters = g.spawnMonsters(g.state.CurrentLevel+1, monsterHealth, monsterAttack)
+	g.updateView()
+	logging.Log(logging.Info, nil, fmt.Sprintf("------------------------------ Level %d Ended ----------------------------", g.state.CurrentLevel))
+
+	immutableFightRounds := commons.NewImmutableList(fightResultSlice)
+	votesResult := commons.MapToImmutable(votes)
+	// strategies are not called when replaying, so there is no internal state to update
+	if g.replay == nil {
+		end.AgentLogs = stages.UpdateInternalStates(g.agents, g.state, immutableFightRounds, &votesResult)
+	}
+
+	end.Deltas = g.state.Deltas()
+	g.notify(func(o Observer) { o.OnLevelEnd(end) })
+	g.verify(Event{Kind: LevelEvent, State: g.state})
+
+	if g.state.CurrentLevel == g.config.NumLevels {
+		g.win()
+		return true
+	}
+	g.state.CurrentLevel++
+	g.updateView()
+	return false
+}
+
+func (g *Game) win() {
+	logging.Log(logging.Info, nil, fmt.Sprintf("Congratulations, The Peasants have escaped the pit with %d remaining.", len(g.agents)))
+	g.finish(logging.Win)
+}
+
+// lose ends the level early, losing the game.
+func (g *Game) lose(end LevelEnd) {
+	end.Lost = true
+	end.Deltas = g.state.Deltas()
+	g.notify(func(o Observer) { o.OnLevelEnd(end) })
+	g.finish(logging.Loss)
+}
+
+func (g *Game) finish(outcome logging.Outcome) {
+	g.finished = true
+	g.outcome = outcome
+	g.verify(Event{Kind: EndEvent, State: g.state, Outcome: &outcome})
+	gameEnd := GameEnd{Level: g.state.CurrentLevel, Outcome: outcome, Survivors: uint(len(g.agents))}
+	g.notify(func(o Observer) { o.OnGameEnd(gameEnd) })
+}
+
+func (g *Game) updateView() {
+	*g.view = g.state.ToView()
+}
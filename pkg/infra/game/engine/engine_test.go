@@ -0,0 +1,481 @@
+package engine_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"infra/config"
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/engine"
+	"infra/game/example"
+	"infra/game/message"
+	"infra/game/message/proposal"
+	"infra/logging"
+	"infra/teams/team1"
+
+	"github.com/benbjohnson/immutable"
+)
+
+func testConfig(seed int64) config.GameConfig {
+	return config.GameConfig{
+		NumLevels:              3,
+		StartingHealthPoints:   1000,
+		StartingAttackStrength: 20,
+		StartingShieldStrength: 20,
+		ThresholdPercentage:    0.6,
+		Stamina:                2000,
+		VotingStrategy:         1,
+		VotingPreferences:      2,
+		Seed:                   seed,
+		StaminaModel:           config.StaminaModel{ItemWeight: 1, CowerStamina: 1, CowerHpPct: 1},
+		MonstersPerLevel:       1,
+		TradeRounds:            5,
+		TradeRoundLimit:        3,
+	}
+}
+
+func TestConcurrentGames(t *testing.T) {
+	t.Parallel()
+
+	var wg sync.WaitGroup
+	logs := make([]logging.GameLog, 2)
+	for i := range logs {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			strategies := map[commons.ID]func() agent.Strategy{"RANDOM": example.NewRandomAgent}
+			_, logs[i] = engine.NewGame(testConfig(int64(i)), strategies).Run()
+		}()
+	}
+	wg.Wait()
+
+	for i, gameLog := range logs {
+		if len(gameLog.Levels) == 0 || len(gameLog.Levels) > 3 {
+			t.Errorf("game %d logged %d levels, expected between 1 and 3", i, len(gameLog.Levels))
+		}
+	}
+}
+
+func TestStepLevel(t *testing.T) {
+	t.Parallel()
+
+	strategies := map[commons.ID]func() agent.Strategy{"RANDOM": example.NewRandomAgent}
+	game := engine.NewGame(testConfig(1), strategies)
+	if game.Config().InitialNumAgents == 0 {
+		t.Fatalf("expected agents to be instantiated")
+	}
+
+	levels := 0
+	for !game.StepLevel() {
+		levels++
+	}
+	if !game.Finished() {
+		t.Errorf("StepLevel reported completion but the game is not finished")
+	}
+	if levels >= 3 {
+		t.Errorf("played %d levels before finishing a 3 level game", levels+1)
+	}
+}
+
+func TestSeededGamesAreReproducible(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		strategies map[commons.ID]func() agent.Strategy
+	}{
+		{"random", map[commons.ID]func() agent.Strategy{"RANDOM": example.NewRandomAgent}},
+		// team 1's agents gossip and propose, so the game also depends on how their messages are ordered
+		{"mixed", map[commons.ID]func() agent.Strategy{"RANDOM": example.NewRandomAgent, "TEAM1": team1.NewSocialAgent}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gameConfig := testConfig(42)
+			gameConfig.AgentQuantities = map[string]uint{"RANDOM": 50, "TEAM1": 50}
+			logs := make([]logging.GameLog, 2)
+			for i := range logs {
+				_, logs[i] = engine.NewGame(gameConfig, tt.strategies).Run()
+			}
+
+			if !reflect.DeepEqual(logs[0], logs[1]) {
+				t.Errorf("two games with the same seed produced different logs")
+			}
+		})
+	}
+}
+
+func TestLootDiscussionIsLogged(t *testing.T) {
+	t.Parallel()
+
+	// monsters are scaled to the number of levels, so a short game rarely gets past its first fight
+	gameConfig := testConfig(1)
+	gameConfig.NumLevels = 60
+	strategies := map[commons.ID]func() agent.Strategy{"RANDOM": example.NewRandomAgent}
+	_, gameLog := engine.NewGame(gameConfig, strategies).Run()
+
+	discussed := false
+	for _, level := range gameLog.Levels {
+		stage := level.LootStage
+		if !stage.Occurred {
+			continue
+		}
+		discussed = true
+		if stage.Rounds == 0 {
+			t.Errorf("level %d: loot stage logged without any discussion rounds", level.LevelStats.CurrentLevel)
+		}
+		if stage.WinningProposal != nil && stage.WinningProposal.Votes == 0 {
+			t.Errorf("level %d: winning proposal %+v logged without votes", level.LevelStats.CurrentLevel, stage.WinningProposal)
+		}
+		for _, entry := range stage.Transcript {
+			if entry.Round < 2 || entry.Round > stage.Rounds || entry.Message == "" {
+				t.Errorf("level %d: malformed transcript entry %+v", level.LevelStats.CurrentLevel, entry)
+			}
+		}
+	}
+	if !discussed {
+		t.Errorf("no loot stage was logged")
+	}
+}
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	gameConfig := testConfig(1)
+	gameConfig.NumLevels = 60
+	strategies := map[commons.ID]func() agent.Strategy{"RANDOM": example.NewRandomAgent}
+	game := engine.NewGame(gameConfig, strategies)
+	for i := 0; i < 3; i++ {
+		if game.StepLevel() {
+			t.Fatalf("game finished on level %d, before it could be checkpointed", game.Level())
+		}
+	}
+
+	var checkpoint bytes.Buffer
+	if err := game.Checkpoint(&checkpoint); err != nil {
+		t.Fatalf("Checkpoint() = %v", err)
+	}
+	resumed, err := engine.Resume(&checkpoint, strategies)
+	if err != nil {
+		t.Fatalf("Resume() = %v", err)
+	}
+	if resumed.Level() != game.Level() {
+		t.Errorf("resumed on level %d; want %d", resumed.Level(), game.Level())
+	}
+
+	outcome, gameLog := game.Run()
+	resumedOutcome, resumedLog := resumed.Run()
+	want, _ := json.Marshal(gameLog)
+	got, _ := json.Marshal(resumedLog)
+	if resumedOutcome != outcome || !bytes.Equal(got, want) {
+		t.Errorf("resumed game played out differently from the original")
+	}
+}
+
+func TestReplayEventLog(t *testing.T) {
+	t.Parallel()
+
+	gameConfig := testConfig(1)
+	gameConfig.NumLevels = 60
+	game := engine.NewGame(gameConfig, map[commons.ID]func() agent.Strategy{"RANDOM": example.NewRandomAgent})
+	var events bytes.Buffer
+	game.RecordEvents(&events)
+	_, gameLog := game.Run()
+	if err := game.EventsErr(); err != nil {
+		t.Fatalf("recording events: %v", err)
+	}
+
+	levels, err := engine.Replay(bytes.NewReader(events.Bytes()))
+	if err != nil {
+		t.Fatalf("Replay() = %v", err)
+	}
+	if levels != uint(len(gameLog.Levels)) {
+		t.Errorf("replayed %d levels; want %d", levels, len(gameLog.Levels))
+	}
+}
+
+// countingObserver counts the levels and deaths it is told about.
+type countingObserver struct {
+	engine.BaseObserver
+	started, ended, deaths int
+	survivors              uint
+}
+
+func (c *countingObserver) OnLevelStart(engine.LevelStart) { c.started++ }
+func (c *countingObserver) OnLevelEnd(engine.LevelEnd)     { c.ended++ }
+func (c *countingObserver) OnAgentDeath(engine.AgentDeath) { c.deaths++ }
+func (c *countingObserver) OnGameEnd(e engine.GameEnd)     { c.survivors = e.Survivors }
+
+func TestObserversSeeEveryLevel(t *testing.T) {
+	t.Parallel()
+
+	game := engine.NewGame(testConfig(1), map[commons.ID]func() agent.Strategy{"RANDOM": example.NewRandomAgent})
+	observer := &countingObserver{}
+	game.Observe(observer)
+	_, gameLog := game.Run()
+
+	if observer.started != len(gameLog.Levels) || observer.ended != len(gameLog.Levels) {
+		t.Errorf("observed %d level starts and %d level ends; want %d", observer.started, observer.ended, len(gameLog.Levels))
+	}
+	if want := int(game.Config().InitialNumAgents) - int(observer.survivors); observer.deaths != want {
+		t.Errorf("observed %d deaths; want %d", observer.deaths, want)
+	}
+}
+
+// stallingAgent holds on to its ballot until its decision budget has run out.
+type stallingAgent struct {
+	example.RandomAgent
+}
+
+func (s *stallingAgent) HandleElectionBallot(baseAgent agent.BaseAgent, _ *decision.ElectionParams) decision.Ballot {
+	<-baseAgent.Context().Done()
+	return decision.Ballot{baseAgent.ID()}
+}
+
+func TestDecisionTimeouts(t *testing.T) {
+	t.Parallel()
+
+	gameConfig := testConfig(1)
+	gameConfig.DecisionTimeout = 200 * time.Millisecond
+	strategies := map[commons.ID]func() agent.Strategy{
+		"STALLING": func() agent.Strategy { return &stallingAgent{} },
+	}
+	game := engine.NewGame(gameConfig, strategies)
+	_, gameLog := game.Run()
+
+	if len(gameLog.Timeouts) == 0 {
+		t.Fatalf("expected overrunning agents to be recorded in the game log")
+	}
+	if uint(len(gameLog.Timeouts)) != game.Config().InitialNumAgents {
+		t.Errorf("%d agents timed out, expected all %d to overrun the first election", len(gameLog.Timeouts), game.Config().InitialNumAgents)
+	}
+}
+
+// panickingAgent has not implemented its ballot yet.
+type panickingAgent struct {
+	example.RandomAgent
+}
+
+func (p *panickingAgent) HandleElectionBallot(agent.BaseAgent, *decision.ElectionParams) decision.Ballot {
+	panic("implement me")
+}
+
+func TestStrategyPanicsAreRecorded(t *testing.T) {
+	t.Parallel()
+
+	strategies := map[commons.ID]func() agent.Strategy{
+		"PANICKING": func() agent.Strategy { return &panickingAgent{} },
+	}
+	game := engine.NewGame(testConfig(1), strategies)
+	_, gameLog := game.Run()
+
+	if uint(len(gameLog.Errors)) < game.Config().InitialNumAgents {
+		t.Fatalf("logged %d errors, expected every agent's ballot to panic", len(gameLog.Errors))
+	}
+	for _, entry := range gameLog.Errors[:game.Config().InitialNumAgents] {
+		if entry["call"] != "HandleElectionBallot" || entry["agentName"] != "PANICKING" || entry["stack"] == "" {
+			t.Errorf("unexpected error entry %v", entry)
+		}
+	}
+}
+
+// cowardlyAgent never fights, so no monster is ever slain.
+type cowardlyAgent struct {
+	example.RandomAgent
+}
+
+func (c *cowardlyAgent) FightActionNoProposal(agent.BaseAgent) decision.FightAction {
+	return decision.Cower
+}
+
+func (c *cowardlyAgent) FightAction(agent.BaseAgent, decision.FightAction, message.Proposal[decision.FightAction]) decision.FightAction {
+	return decision.Cower
+}
+
+func (c *cowardlyAgent) FightResolution(_ agent.BaseAgent, _ commons.ImmutableList[proposal.Rule[decision.FightAction]], proposedActions immutable.Map[commons.ID, decision.FightAction]) immutable.Map[commons.ID, decision.FightAction] {
+	return proposedActions
+}
+
+func TestStalemateRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		rule       config.StalemateRule
+		wantLevels int
+	}{
+		{config.StalemateLose, 1},
+		{config.StalemateRetreat, 2},
+		{config.StalemateEnrage, 1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.rule), func(t *testing.T) {
+			t.Parallel()
+
+			gameConfig := testConfig(1)
+			gameConfig.NumLevels = 10
+			gameConfig.ThresholdPercentage = 0
+			gameConfig.MaxFightRounds = 1
+			gameConfig.StalemateRule = tt.rule
+			gameConfig.EnragePercentage = 50
+			strategies := map[commons.ID]func() agent.Strategy{"COWARDLY": func() agent.Strategy { return &cowardlyAgent{} }}
+			_, gameLog := engine.NewGame(gameConfig, strategies).Run()
+
+			if len(gameLog.Levels) < tt.wantLevels {
+				t.Fatalf("played %d levels, expected at least %d", len(gameLog.Levels), tt.wantLevels)
+			}
+			fightStage := gameLog.Levels[0].FightStage
+			if fightStage.Stalemate != string(tt.rule) {
+				t.Errorf("FightStage.Stalemate = %q, expected %q", fightStage.Stalemate, tt.rule)
+			}
+			if tt.rule != config.StalemateEnrage && len(fightStage.Rounds) != 1 {
+				t.Errorf("fought %d rounds, expected the fight to stop after 1", len(fightStage.Rounds))
+			}
+			if rounds := fightStage.Rounds; tt.rule == config.StalemateEnrage && len(rounds) > 2 && rounds[2].MonsterAttack <= rounds[1].MonsterAttack {
+				t.Errorf("monster attack went from %d to %d, expected it to grow once enraged", rounds[1].MonsterAttack, rounds[2].MonsterAttack)
+			}
+		})
+	}
+}
+
+// mutinousAgent urges the others to vote against the leader, and votes against it once urged.
+type mutinousAgent struct {
+	example.RandomAgent
+	urged bool
+}
+
+func (m *mutinousAgent) HandleConfidenceMessage(msg message.TaggedMessage, baseAgent agent.BaseAgent) {
+	switch stance := msg.Message().(type) {
+	case message.StartConfidencePoll:
+		m.urged = false
+		baseAgent.BroadcastBlockingMessage(message.ConfidenceStance{Intent: decision.Negative})
+	case message.ConfidenceStance:
+		m.urged = m.urged || stance.Intent == decision.Negative
+	}
+}
+
+func (m *mutinousAgent) HandleConfidencePoll(agent.BaseAgent) decision.Intent {
+	if m.urged {
+		return decision.Negative
+	}
+	return decision.Positive
+}
+
+func TestRecallRules(t *testing.T) {
+	t.Parallel()
+
+	gameConfig := testConfig(1)
+	// monsters deal no damage when every agent must survive, and retreat after a round
+	gameConfig.NumLevels = 4
+	gameConfig.ThresholdPercentage = 1
+	gameConfig.MaxFightRounds = 1
+	gameConfig.StalemateRule = config.StalemateRetreat
+	gameConfig.Recall = config.RecallRules{Quorum: 50, Supermajority: 90, Cooldown: 1, Outcome: config.RecallDeputy}
+	strategies := map[commons.ID]func() agent.Strategy{"MUTINOUS": func() agent.Strategy { return &mutinousAgent{} }}
+	_, gameLog := engine.NewGame(gameConfig, strategies).Run()
+	if len(gameLog.Levels) < 4 {
+		t.Fatalf("played %d levels, expected 4", len(gameLog.Levels))
+	}
+
+	// the deputy takes over from the leader recalled on level 2, and the one recalled on level 4 has no deputy
+	levels := gameLog.Levels
+	if vote := levels[1].VONCStage; !vote.Recalled || vote.Deputy != levels[0].ElectionStage.RunnerUp || vote.Election != nil {
+		t.Errorf("level 2 vote = %+v, expected the deputy elected on level 1 to take over", vote)
+	}
+	if levels[2].VONCStage.Occurred {
+		t.Error("a confidence vote was held on level 3, during the cooldown")
+	}
+	if vote := levels[3].VONCStage; !vote.Recalled || vote.Deputy != "" || vote.Election == nil {
+		t.Errorf("level 4 vote = %+v, expected a snap election", vote)
+	}
+}
+
+// martyrAgent gives all its health to the HP pool while it leads, and names a deputy if naming is set.
+type martyrAgent struct {
+	example.RandomAgent
+	naming bool
+}
+
+func (m *martyrAgent) CreateManifesto(baseAgent agent.BaseAgent) *decision.Manifesto {
+	manifesto := decision.NewManifesto(false, false, 10, 5)
+	if !m.naming {
+		return manifesto
+	}
+	view := baseAgent.View()
+	agents := view.AgentState()
+	iterator := agents.Iterator()
+	for !iterator.Done() {
+		if id, _, _ := iterator.Next(); id != baseAgent.ID() {
+			return manifesto.WithDeputy(id)
+		}
+	}
+	return manifesto
+}
+
+func (m *martyrAgent) DonateToHpPool(baseAgent agent.BaseAgent) uint {
+	if view := baseAgent.View(); view.CurrentLeader() == baseAgent.ID() {
+		return baseAgent.AgentState().Hp
+	}
+	return 0
+}
+
+func TestSuccession(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		rule          config.Succession
+		naming        bool
+		wantSuccessor engine.Successor
+	}{
+		{config.SuccessionRunnerUp, false, engine.SuccessorRunnerUp},
+		{config.SuccessionLeaderless, true, engine.SuccessorDeputy},
+		{config.SuccessionElection, false, engine.SuccessorElection},
+		{config.SuccessionLeaderless, false, engine.SuccessorNone},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.wantSuccessor), func(t *testing.T) {
+			t.Parallel()
+
+			gameConfig := testConfig(1)
+			gameConfig.NumLevels = 2
+			gameConfig.ThresholdPercentage = 1
+			gameConfig.MaxFightRounds = 1
+			gameConfig.StalemateRule = config.StalemateRetreat
+			gameConfig.Succession = tt.rule
+			strategies := map[commons.ID]func() agent.Strategy{"MARTYR": func() agent.Strategy { return &martyrAgent{naming: tt.naming} }}
+			_, gameLog := engine.NewGame(gameConfig, strategies).Run()
+			if len(gameLog.Levels) < 2 {
+				t.Fatalf("played %d levels, expected 2", len(gameLog.Levels))
+			}
+
+			// the leader elected on level 1 dies donating its health at the end of the level
+			election, changes := gameLog.Levels[0].ElectionStage, gameLog.Levels[0].LeaderChanges
+			if len(changes) != 1 || changes[0].Previous != election.Winner || changes[0].Reason != string(engine.LeaderDied) {
+				t.Fatalf("level 1 leader changes = %+v, expected %s to die", changes, election.Winner)
+			}
+			if changes[0].Successor != string(tt.wantSuccessor) {
+				t.Errorf("successor = %s, expected %s", changes[0].Successor, tt.wantSuccessor)
+			}
+			want := map[engine.Successor]commons.ID{
+				engine.SuccessorDeputy:   election.Manifesto.Deputy,
+				engine.SuccessorRunnerUp: election.RunnerUp,
+			}[tt.wantSuccessor]
+			if changes[0].Leader != want || gameLog.Levels[1].LevelStats.LeaderBeforeElection != want {
+				t.Errorf("%s took over, leading on level 2; expected %q", changes[0].Leader, want)
+			}
+			if elected := gameLog.Levels[1].ElectionStage.Occurred; elected != (want == "") {
+				t.Errorf("level 2 election held = %t, expected %t", elected, want == "")
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"infra/config"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/message/proposal"
+	"infra/game/stage/loot"
+	"infra/game/stage/trade"
+	"infra/game/state"
+	"infra/game/tally"
+	"infra/logging"
+
+	"github.com/benbjohnson/immutable"
+)
+
+// EventKind says which engine step an Event records.
+type EventKind string
+
+const (
+	// StartEvent holds everything the engine needs to play the game from where recording began.
+	StartEvent EventKind = "start"
+	// The decisions agents have a say in. A replay feeds these to the engine instead of asking strategies.
+	// A RunoffEvent holds the ballots of the second round of an election, when the voting rule holds one.
+	ElectionEvent   EventKind = "election"
+	RunoffEvent     EventKind = "runoff"
+	ConfidenceEvent EventKind = "confidence"
+	ItemsEvent      EventKind = "items"
+	FightEvent      EventKind = "fight"
+	LootEvent       EventKind = "loot"
+	TradeEvent      EventKind = "trade"
+	DonationEvent   EventKind = "donation"
+	// The outcomes of the engine's own logic. A replay checks that it arrives at the same ones.
+	DamageEvent EventKind = "damage"
+	LevelEvent  EventKind = "level"
+	EndEvent    EventKind = "end"
+)
+
+// Event is one line of the event log. Only the fields of its kind are set.
+type Event struct {
+	Kind  EventKind
+	Level uint
+	Round uint `json:",omitempty"`
+	// Draws is how many values had been drawn from the game's random source at the time of the event
+	Draws uint64
+
+	Start *Start `json:",omitempty"`
+
+	Manifestos map[commons.ID]decision.Manifesto `json:",omitempty"`
+	Ballots    map[commons.ID]decision.Ballot    `json:",omitempty"`
+	Votes      map[commons.ID]decision.Intent    `json:",omitempty"`
+	Items      map[commons.ID]loot.ItemChoice    `json:",omitempty"`
+	// Proposals are the proposals put to the leader in a fight or loot discussion, with their votes
+	Proposals    []ProposalEvent                     `json:",omitempty"`
+	FightActions map[commons.ID]decision.FightAction `json:",omitempty"`
+	// FightTargets are the monsters the agents that attack or defend chose to fight
+	FightTargets map[commons.ID]decision.MonsterIdx `json:",omitempty"`
+	// Defectors are the agents marked as fight defectors when the fight discussion was resolved
+	Defectors []commons.ID `json:",omitempty"`
+	// Breaches are the breaches of its manifesto's policies in the leader's resolution of a fight or loot discussion
+	Breaches   []decision.Breach               `json:",omitempty"`
+	Allocation map[commons.ID][]commons.ItemID `json:",omitempty"`
+	Trades     []trade.Move                    `json:",omitempty"`
+	Donations  map[commons.ID]uint             `json:",omitempty"`
+	Damage     *Damage                         `json:",omitempty"`
+	State      *state.State                    `json:",omitempty"`
+	Outcome    *logging.Outcome                `json:",omitempty"`
+}
+
+// Start is the game as it was when recording began.
+type Start struct {
+	Config   config.GameConfig
+	State    state.State
+	TermLeft uint
+	Source   commons.SourceState
+	// Agents maps the ID of every living agent to its team name
+	Agents map[commons.ID]string
+}
+
+type ProposalEvent struct {
+	ID       commons.ProposalID
+	Proposer commons.ID
+	Votes    uint
+	Rules    []string
+}
+
+// Damage is the damage dealt in a fight round: to the monster, and to each agent that lost health.
+type Damage struct {
+	Monster uint
+	Agents  map[commons.ID]uint `json:",omitempty"`
+}
+
+// eventLog writes events as JSON lines. After the first failed write it stops writing and keeps the error.
+type eventLog struct {
+	encoder *json.Encoder
+	err     error
+}
+
+func (l *eventLog) write(e Event) {
+	if l.err == nil {
+		l.err = l.encoder.Encode(e)
+	}
+}
+
+// RecordEvents starts writing the game's event log to w, from the next level onwards.
+// The log can be checked with Replay. It must only be called between levels.
+func (g *Game) RecordEvents(w io.Writer) {
+	g.events = &eventLog{encoder: json.NewEncoder(w)}
+	agents := make(map[commons.ID]string, len(g.agents))
+	for id, a := range g.agents {
+		agents[id] = a.BaseAgent.Name()
+	}
+	g.record(Event{Kind: StartEvent, Start: &Start{
+		Config:   g.config,
+		State:    *g.state,
+		TermLeft: g.termLeft,
+		Source:   g.source.State(),
+		Agents:   agents,
+	}})
+}
+
+// EventsErr returns the error that stopped the event log being written, if any.
+func (g *Game) EventsErr() error {
+	if g.events == nil {
+		return nil
+	}
+	return g.events.err
+}
+
+func (g *Game) record(e Event) {
+	if g.events == nil {
+		return
+	}
+	e.Level = g.state.CurrentLevel
+	g.events.write(e)
+}
+
+// decide returns the event holding a decision of the agents. When replaying it is the next event in
+// the log; otherwise ask gets the decision from the agents and it is recorded.
+func (g *Game) decide(kind EventKind, round uint, ask func() Event) Event {
+	if g.replay != nil {
+		return g.replay.next(g, kind, round)
+	}
+	e := ask()
+	e.Kind, e.Round, e.Draws = kind, round, g.source.State().Draws
+	g.record(e)
+	return e
+}
+
+// verify records an outcome of the engine's own logic or, when replaying, checks it against the log.
+func (g *Game) verify(e Event) {
+	e.Draws = g.source.State().Draws
+	if g.replay != nil {
+		g.replay.check(g, e)
+		return
+	}
+	g.record(e)
+}
+
+func proposalEvents[A decision.ProposalAction](propTally *tally.Tally[A]) []ProposalEvent {
+	proposals := make([]ProposalEvent, 0, len(propTally.ProposalMap()))
+	for _, id := range commons.SortedKeys(propTally.ProposalMap()) {
+		rules := propTally.ProposalMap()[id]
+		proposals = append(proposals, ProposalEvent{
+			ID:       id,
+			Proposer: propTally.Proposer(id),
+			Votes:    propTally.ProposalTally()[id],
+			Rules:    describeRules(rules),
+		})
+	}
+	return proposals
+}
+
+func describeRules[A decision.ProposalAction](rules commons.ImmutableList[proposal.Rule[A]]) []string {
+	actions := make([]string, 0, rules.Len())
+	iterator := rules.Iterator()
+	for !iterator.Done() {
+		rule, _ := iterator.Next()
+		actions = append(actions, fmt.Sprint(rule.Action()))
+	}
+	return actions
+}
+
+func allocationLists(allocation immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]]) map[commons.ID][]commons.ItemID {
+	lists := make(map[commons.ID][]commons.ItemID, allocation.Len())
+	iterator := allocation.Iterator()
+	for !iterator.Done() {
+		id, items, _ := iterator.Next()
+		itemIterator := items.Iterator()
+		for !itemIterator.Done() {
+			item, _, _ := itemIterator.Next()
+			lists[id] = append(lists[id], item)
+		}
+	}
+	return lists
+}
+
+func allocationMap(lists map[commons.ID][]commons.ItemID) immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]] {
+	allocation := make(map[commons.ID]immutable.SortedMap[commons.ItemID, struct{}], len(lists))
+	for id, items := range lists {
+		allocation[id] = commons.ListToImmutableSortedSet(items)
+	}
+	return commons.MapToImmutable(allocation)
+}
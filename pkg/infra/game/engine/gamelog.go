@@ -0,0 +1,336 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+
+	"infra/config"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/state"
+	"infra/logging"
+
+	"github.com/benbjohnson/immutable"
+)
+
+// gameLogger is the Observer that builds the game log, which logging.OutputLog writes to file.
+// Every game has one, ahead of any other observers.
+type gameLogger struct {
+	BaseObserver
+	log logging.GameLog
+	// level is the log of the level being played
+	level logging.LevelStages
+}
+
+func (l *gameLogger) OnLevelStart(e LevelStart) {
+	var hp, attack, shield, stamina uint
+	iterator := e.Agents.Iterator()
+	for !iterator.Done() {
+		_, agentState, _ := iterator.Next()
+		hp += agentState.Hp
+		attack += agentState.TotalAttack()
+		shield += agentState.TotalDefense()
+		stamina += agentState.Stamina
+	}
+	if agents := uint(e.Agents.Len()); agents > 0 {
+		hp, attack, shield, stamina = hp/agents, attack/agents, shield/agents, stamina/agents
+	}
+
+	lead := state.Monsters(commons.ImmutableListToSlice(e.Monsters)).Lead()
+	monsters := make([]logging.MonsterLog, 0, e.Monsters.Len())
+	monsterIterator := e.Monsters.Iterator()
+	for !monsterIterator.Done() {
+		_, m := monsterIterator.Next()
+		monsters = append(monsters, logging.MonsterLog{
+			Name:     m.Name,
+			Ability:  string(m.Ability),
+			Strength: m.Strength,
+			Armour:   m.Armour,
+			Health:   m.Health,
+			Attack:   m.Attack,
+		})
+	}
+
+	l.level = logging.LevelStages{LevelStats: logging.LevelStats{
+		NumberOfAgents:       uint(e.Agents.Len()),
+		CurrentLevel:         e.Level,
+		LeaderBeforeElection: e.Leader,
+		LeaderAfterElection:  e.Leader,
+		HPPool:               e.HpPool,
+		MonsterHealth:        e.MonsterHealth,
+		MonsterAttack:        e.MonsterAttack,
+		Monster:              lead.Name,
+		MonsterAbility:       string(lead.Ability),
+		MonsterStrength:      lead.Strength,
+		Monsters:             monsters,
+		AverageAgentHealth:   hp,
+		AverageAgentAttack:   attack,
+		AverageAgentShield:   shield,
+		AverageAgentStamina:  stamina,
+	}}
+}
+
+func (l *gameLogger) OnElection(e Election) {
+	if !e.Succession {
+		l.level.LevelStats.LeaderAfterElection = e.Winner
+	}
+
+	candidates := make(map[commons.ID]logging.CandidateLog, e.Candidates.Len())
+	candidateIterator := e.Candidates.Iterator()
+	for !candidateIterator.Done() {
+		id, candidate, _ := candidateIterator.Next()
+		candidates[id] = logging.CandidateLog{Team: candidate.Team, Manifesto: manifestoLog(candidate.Manifesto)}
+	}
+	counts := make([]logging.CountLog, 0, e.Counts.Len())
+	countIterator := e.Counts.Iterator()
+	for !countIterator.Done() {
+		round, _ := countIterator.Next()
+		counts = append(counts, logging.CountLog{Scores: commons.ImmutableToMap(round.Scores), Eliminated: ids(round.Eliminated)})
+	}
+	stage := logging.ElectionStage{
+		Occurred:   true,
+		Winner:     e.Winner,
+		Team:       e.Team,
+		Manifesto:  manifestoLog(e.Manifesto),
+		RunnerUp:   e.RunnerUp,
+		Strategy:   logging.VotingStrategy(e.Strategy),
+		Candidates: candidates,
+		Ballots:    l.ballotsLog(e.Ballots),
+		Counts:     counts,
+	}
+	if e.RunoffBallots.Len() > 0 {
+		runoff := l.ballotsLog(e.RunoffBallots)
+		stage.RunoffBallots = &runoff
+	}
+
+	// an election after a lost confidence vote is logged as part of the vote, and one after the leader died as
+	// part of the change of leader it completes
+	if e.NoConfidence {
+		l.level.VONCStage.Election = &stage
+		return
+	}
+	if changes := l.level.LeaderChanges; e.Succession && len(changes) > 0 {
+		changes[len(changes)-1].Election = &stage
+		return
+	}
+	l.level.ElectionStage = stage
+}
+
+func manifestoLog(m decision.Manifesto) logging.ManifestoLog {
+	return logging.ManifestoLog{
+		FightImposition:     m.FightDecisionPower(),
+		LootImposition:      m.LootDecisionPower(),
+		TermLength:          m.TermLength(),
+		ThresholdPercentage: m.OverthrowThreshold(),
+		Policies: logging.PolicyLog{
+			HpPoolDonation:     m.Policies().HpPoolDonation,
+			TradeTax:           m.Policies().TradeTax,
+			LootPriority:       m.Policies().LootPriority.String(),
+			CowerCap:           m.Policies().CowerCap,
+			DefectorPunishment: m.Policies().DefectorPunishment.String(),
+			DefectorFine:       m.Policies().DefectorFine,
+		},
+		Deputy: m.Deputy(),
+	}
+}
+
+// ballotsLog logs the ballots by voter or, if the game anonymises them, in order of their contents.
+func (l *gameLogger) ballotsLog(ballots immutable.Map[commons.ID, immutable.List[commons.ID]]) logging.BallotsLog {
+	if !l.log.Config.AnonymousBallots {
+		byVoter := make(map[commons.ID][]commons.ID, ballots.Len())
+		iterator := ballots.Iterator()
+		for !iterator.Done() {
+			voter, ballot, _ := iterator.Next()
+			byVoter[voter] = ids(ballot)
+		}
+		return logging.BallotsLog{ByVoter: byVoter}
+	}
+	anonymous := make([][]commons.ID, 0, ballots.Len())
+	iterator := ballots.Iterator()
+	for !iterator.Done() {
+		_, ballot, _ := iterator.Next()
+		anonymous = append(anonymous, ids(ballot))
+	}
+	sort.Slice(anonymous, func(i, j int) bool {
+		return strings.Join(anonymous[i], ",") < strings.Join(anonymous[j], ",")
+	})
+	return logging.BallotsLog{Anonymous: anonymous}
+}
+
+func (l *gameLogger) OnConfidenceVote(e ConfidenceVote) {
+	l.level.VONCStage = logging.VONCStage{
+		Occurred:  true,
+		For:       e.For,
+		Against:   e.Against,
+		Abstain:   e.Abstain,
+		Threshold: e.Threshold,
+		Quorate:   e.Quorate,
+		Recalled:  e.Ousted,
+		Deputy:    e.Deputy,
+	}
+}
+
+func (l *gameLogger) OnFightRound(e FightRound) {
+	l.level.FightStage.Occurred = true
+	l.level.FightStage.Rounds = append(l.level.FightStage.Rounds, logging.FightLog{
+		AttackingAgents:  ids(e.AttackingAgents),
+		ShieldingAgents:  ids(e.ShieldingAgents),
+		CoweringAgents:   ids(e.CoweringAgents),
+		AttackSum:        e.AttackSum,
+		ShieldSum:        e.ShieldSum,
+		MonsterAttack:    e.MonsterAttack,
+		Ability:          string(e.Ability),
+		AbilityTriggered: e.AbilityTriggered,
+		AgentsRemaining:  e.AgentsRemaining,
+		Monsters:         monsterFights(e),
+	})
+}
+
+func monsterFights(e FightRound) []logging.MonsterFightLog {
+	fights := make([]logging.MonsterFightLog, 0, e.Monsters.Len())
+	iterator := e.Monsters.Iterator()
+	for !iterator.Done() {
+		i, m := iterator.Next()
+		fight := logging.MonsterFightLog{Health: m.Health}
+		if i < e.AttackSums.Len() {
+			fight.AttackSum = e.AttackSums.Get(i)
+			fight.ShieldSum = e.ShieldSums.Get(i)
+		}
+		fights = append(fights, fight)
+	}
+	return fights
+}
+
+func (l *gameLogger) OnLootAllocated(e LootAllocated) {
+	l.level.LootStage = e.Discussion
+}
+
+func (l *gameLogger) OnHpPoolDonation(e HpPoolDonation) {
+	l.level.HPPoolStage = logging.HPPoolStage{
+		Occurred:         true,
+		DonatedThisRound: e.NewPool - e.OldPool,
+		OldHPPool:        e.OldPool,
+		NewHPPool:        e.NewPool,
+	}
+}
+
+func (l *gameLogger) OnPolicyBreach(e PolicyBreach) {
+	l.level.Breaches = append(l.level.Breaches, logging.BreachLog{
+		Leader: e.Leader,
+		Policy: string(e.Policy),
+		Agents: ids(e.Agents),
+	})
+}
+
+func (l *gameLogger) OnLeaderChanged(e LeaderChanged) {
+	l.level.LeaderChanges = append(l.level.LeaderChanges, logging.LeaderChangeLog{
+		Previous:  e.Previous,
+		Leader:    e.Leader,
+		Reason:    string(e.Reason),
+		Successor: string(e.Successor),
+	})
+}
+
+func (l *gameLogger) OnLevelEnd(e LevelEnd) {
+	l.level.LevelStats.SkippedThroughHpPool = e.SkippedThroughHpPool
+	l.level.FightStage.Stalemate = e.Stalemate
+	l.level.AgentLogs = e.AgentLogs
+	l.level.AgentDeltas = make(map[commons.ID]logging.AgentDelta, len(e.Deltas))
+	for id, delta := range e.Deltas {
+		l.level.AgentDeltas[id] = agentDelta(delta)
+	}
+	l.log.LogToFile(logging.Info, nil, "", l.level)
+}
+
+func (l *gameLogger) OnGameEnd(e GameEnd) {
+	l.log.Outcome = e.Outcome
+}
+
+func agentDelta(delta state.Delta) logging.AgentDelta {
+	byCause := func(changes map[state.Cause]int) map[string]int {
+		if len(changes) == 0 {
+			return nil
+		}
+		logged := make(map[string]int, len(changes))
+		for cause, change := range changes {
+			logged[string(cause)] = change
+		}
+		return logged
+	}
+	return logging.AgentDelta{
+		Hp:          byCause(delta.Hp),
+		Stamina:     byCause(delta.Stamina),
+		ItemsGained: delta.ItemsGained,
+		ItemsLost:   delta.ItemsLost,
+		Died:        string(delta.Died),
+	}
+}
+
+// logConfig describes the configuration of a game played by agents, the number of which is given by team.
+func logConfig(c config.GameConfig, agents map[string]uint) logging.Config {
+	damageModel := c.DamageModel
+	if damageModel == "" {
+		damageModel = config.DamageEven
+	}
+	tieBreak := c.TieBreak
+	if tieBreak == "" {
+		tieBreak = config.TieBreakRandom
+	}
+	recallOutcome := c.Recall.Outcome
+	if recallOutcome == "" {
+		recallOutcome = config.RecallElection
+	}
+	succession := c.Succession
+	if succession == "" {
+		succession = config.SuccessionRunnerUp
+	}
+	return logging.Config{
+		Mode:              logging.Default,
+		Levels:            c.NumLevels,
+		StartingHP:        c.StartingHealthPoints,
+		StartingAttack:    c.StartingAttackStrength,
+		StartingShield:    c.StartingShieldStrength,
+		BaseStamina:       c.Stamina,
+		PassThreshold:     c.ThresholdPercentage,
+		VotingStrategy:    logging.VotingStrategy(c.VotingStrategy),
+		VotingPreferences: c.VotingPreferences,
+		TieBreak:          string(tieBreak),
+		AnonymousBallots:  c.AnonymousBallots,
+		AgentRandomQty:    agents["RANDOM"],
+		AgentTeam1Qty:     agents["TEAM1"],
+		AgentTeam2Qty:     agents["TEAM2"],
+		AgentTeam3Qty:     agents["TEAM3"],
+		AgentTeam4Qty:     agents["TEAM4"],
+		AgentTeam5Qty:     agents["TEAM5"],
+		AgentTeam6Qty:     agents["TEAM6"],
+		Agents:            agents,
+		Seed:              c.Seed,
+		DamageModel:       string(damageModel),
+		Stamina: logging.StaminaConfig{
+			AttackCost:   c.StaminaModel.AttackCost,
+			DefendCost:   c.StaminaModel.DefendCost,
+			ItemWeight:   c.StaminaModel.ItemWeight,
+			CowerStamina: c.StaminaModel.CowerStamina,
+			CowerHpPct:   c.StaminaModel.CowerHpPct,
+			RoundRegen:   c.StaminaModel.RoundRegen,
+			LevelRegen:   c.StaminaModel.LevelRegen,
+		},
+		MonstersPerLevel: c.MonstersPerLevel,
+		Recall: logging.RecallConfig{
+			QuorumPct:        c.Recall.Quorum,
+			SupermajorityPct: c.Recall.Supermajority,
+			CountAbstentions: c.Recall.CountAbstentions,
+			CooldownLevels:   c.Recall.Cooldown,
+			Outcome:          string(recallOutcome),
+		},
+		Succession: string(succession),
+	}
+}
+
+// ids returns the IDs in list, or nil if there are none.
+func ids(list immutable.List[commons.ID]) []commons.ID {
+	if list.Len() == 0 {
+		return nil
+	}
+	return commons.ImmutableListToSlice(list)
+}
@@ -0,0 +1,517 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+
+	"infra/config"
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/monster"
+	"infra/game/stage/confidence"
+	"infra/game/stage/discussion"
+	"infra/game/stage/election"
+	"infra/game/stage/fight"
+	"infra/game/state"
+	"infra/logging"
+)
+
+/*
+	Communication Helpers
+*/
+
+// connectAgents gives every living agent a fresh Communication with all the others as peers.
+func (g *Game) connectAgents() {
+	ids := commons.SortedKeys(g.agents)
+	for id, a := range g.agents {
+		peers := make([]commons.ID, 0, len(ids)-1)
+		for _, peer := range ids {
+			if peer != id {
+				peers = append(peers, peer)
+			}
+		}
+		a.SetCommunication(agent.NewCommunication(peers))
+	}
+}
+
+func (g *Game) discussionBudget() discussion.Budget {
+	return discussion.Budget{Rounds: g.config.MaxDiscussionRounds, Messages: g.config.MaxDiscussionMessages}
+}
+
+/*
+	Supervision Helpers
+*/
+
+// Timeout implements agent.Monitor, counting the agent's overruns in the game log.
+func (g *Game) Timeout(agentID commons.ID, agentName string, call string) {
+	g.logMu.Lock()
+	defer g.logMu.Unlock()
+
+	if g.logger.log.Timeouts == nil {
+		g.logger.log.Timeouts = make(map[commons.ID]uint)
+	}
+	g.logger.log.Timeouts[agentID]++
+	logging.Log(logging.Warn, logging.LogField{
+		"agentID":   agentID,
+		"agentName": agentName,
+		"call":      call,
+		"budget":    g.config.DecisionTimeout.String(),
+	}, "Agent overran its decision budget, using the default action")
+}
+
+// Panic implements agent.Monitor, recording the panic and its stack as an error in the game log.
+func (g *Game) Panic(agentID commons.ID, agentName string, call string, value any, stack []byte) {
+	g.logMu.Lock()
+	defer g.logMu.Unlock()
+
+	g.logger.log.LogToFile(logging.Error, logging.LogField{
+		"agentID":   agentID,
+		"agentName": agentName,
+		"call":      call,
+		"panic":     fmt.Sprint(value),
+		"stack":     string(stack),
+	}, "Agent strategy panicked, using the default action", logging.LevelStages{})
+}
+
+/*
+	Election Helpers
+*/
+
+// electionCause is why an election is held.
+type electionCause uint
+
+const (
+	// termEnded is the election at the start of a level once the leader's term is over, or it has died.
+	termEnded electionCause = iota
+	// noConfidence is the snap election after the leader lost a confidence vote.
+	noConfidence
+	// successionElection is the snap election after the leader died with no one to take over.
+	successionElection
+)
+
+// runElection elects a new leader, returning the length of its term.
+func (g *Game) runElection(cause electionCause) uint {
+	strategy := decision.VotingStrategy(g.config.VotingStrategy)
+	votes := g.decide(ElectionEvent, 0, func() Event {
+		manifestos, ballots := election.CollectVotes(g.state, g.agents, strategy, g.config.VotingPreferences)
+		return Event{Manifestos: manifestos, Ballots: ballots}
+	})
+	rule := election.NewVotingRule(strategy)
+	tieBreak := election.NewTieBreaker(g.config.TieBreak, g.state.CurrentLeader, g.rng)
+	manifestos, ballots := votes.Manifestos, votes.Ballots
+	var rounds []election.Round
+	var runoffBallots map[commons.ID]decision.Ballot
+	if finalists, round := election.Finalists(manifestos, ballots, rule, tieBreak); finalists != nil {
+		runoff := g.decide(RunoffEvent, 0, func() Event {
+			return Event{Ballots: election.CollectRunoffBallots(g.state, g.agents, finalists, g.config.VotingPreferences)}
+		})
+		rounds = append(rounds, round)
+		manifestos, ballots, runoffBallots = finalists, runoff.Ballots, runoff.Ballots
+	}
+	count, manifesto := election.CountVotes(manifestos, ballots, rule, tieBreak)
+	electedAgent := count.Winner
+	termLeft := manifesto.TermLength()
+	g.state.LeaderManifesto = manifesto
+	g.state.CurrentLeader = electedAgent
+	g.state.Deputy = manifesto.Deputy()
+	g.state.RunnerUp = count.RunnerUp()
+	g.updateView()
+
+	candidates := make(map[commons.ID]Candidate, len(votes.Manifestos))
+	for id, m := range votes.Manifestos {
+		candidates[id] = Candidate{Team: g.agents[id].BaseAgent.Name(), Manifesto: m}
+	}
+	e := Election{
+		Level:         g.state.CurrentLevel,
+		Winner:        electedAgent,
+		Team:          g.agents[electedAgent].BaseAgent.Name(),
+		Manifesto:     manifesto,
+		RunnerUp:      g.state.RunnerUp,
+		NoConfidence:  cause == noConfidence,
+		Succession:    cause == successionElection,
+		Strategy:      strategy,
+		Candidates:    commons.MapToImmutable(candidates),
+		Ballots:       immutableBallots(votes.Ballots),
+		RunoffBallots: immutableBallots(runoffBallots),
+		Counts:        countRounds(append(rounds, count.Rounds...)),
+	}
+	g.notify(func(o Observer) { o.OnElection(e) })
+	return termLeft
+}
+
+// runConfidenceVote asks the agents whether the leader keeps its post. A recalled leader is replaced by its deputy
+// or by a snap election, as the recall rules decide, which returns the new term.
+func (g *Game) runConfidenceVote(termLeft uint) (uint, map[decision.Intent]uint) {
+	ballots := g.decide(ConfidenceEvent, 0, func() Event {
+		return Event{Votes: confidence.Poll(*g.state, g.agents, g.discussionBudget())}
+	})
+	rules := g.config.Recall
+	result := confidence.Count(ballots.Votes, uint(len(g.agents)), g.state.LeaderManifesto.OverthrowThreshold(), rules)
+	leaderName := g.agents[g.state.CurrentLeader].BaseAgent.Name()
+
+	logging.Log(logging.Info, logging.LogField{
+		"positive":  result.For,
+		"negative":  result.Against,
+		"abstain":   result.Abstain,
+		"threshold": result.Threshold,
+		"quorate":   result.Quorate,
+		"leader":    g.state.CurrentLeader,
+		"team":      leaderName,
+	}, "Confidence Vote")
+
+	vote := ConfidenceVote{
+		Level:     g.state.CurrentLevel,
+		Leader:    g.state.CurrentLeader,
+		For:       result.For,
+		Against:   result.Against,
+		Abstain:   result.Abstain,
+		Threshold: result.Threshold,
+		Quorate:   result.Quorate,
+		Ousted:    result.Recalled,
+	}
+	via := SuccessorElection
+	if vote.Ousted && rules.Outcome == config.RecallDeputy {
+		vote.Deputy, via = g.successor(true)
+	}
+	g.notify(func(o Observer) { o.OnConfidenceVote(vote) })
+
+	if vote.Ousted {
+		logging.Log(logging.Info, nil, fmt.Sprintf("%s got ousted", g.state.CurrentLeader))
+		g.state.LastRecall = g.state.CurrentLevel
+		if vote.Deputy != "" {
+			g.takeOver(vote.Deputy)
+		} else {
+			termLeft = g.runElection(noConfidence)
+		}
+		g.changeLeader(vote.Leader, LeaderRecalled, via)
+	}
+	votes := make(map[decision.Intent]uint)
+	for _, intent := range ballots.Votes {
+		votes[intent]++
+	}
+	return termLeft, votes
+}
+
+/*
+	Fight Helpers
+*/
+
+// damageCalculation applies the round's attack to the monster and the monster's attack and ability to
+// the agents, reporting whether the ability had any effect.
+func (g *Game) damageCalculation(fightRoundResult decision.FightResult) (abilityTriggered bool) {
+	// a monster nobody fights turns on the cowering agents, so that with a single monster they only take
+	// damage when every agent cowers
+	for i := range g.state.Monsters {
+		m := &g.state.Monsters[i]
+		if m.Health == 0 {
+			continue
+		}
+		attacking, shielding := fightRoundResult.Fighting(decision.MonsterIdx(i))
+		if len(attacking) == 0 && len(shielding) == 0 {
+			targets := g.targets(g.alive(fightRoundResult.CoweringAgents), decision.Cower)
+			if len(targets) > 0 {
+				fight.DealDamage(g.damageModel().Split(m.Attack, targets, g.rng), g.agents, g.state)
+			}
+			continue
+		}
+
+		attackSum := fightRoundResult.AttackSums[i]
+		m.Health = commons.SaturatingSub(m.Health, attackSum-attackSum*m.Armour/100)
+		if m.Health > 0 {
+			agentsFighting := append(attacking, shielding...)
+			shieldSum := fightRoundResult.ShieldSums[i]
+			if m.Ability == monster.PierceShields {
+				pierced := shieldSum * m.Strength / 100
+				shieldSum -= pierced
+				abilityTriggered = pierced > 0 || abilityTriggered
+			}
+			if shieldSum < m.Attack {
+				damageTaken := m.Attack - shieldSum
+				targets := append(g.targets(g.alive(attacking), decision.Attack), g.targets(g.alive(shielding), decision.Defend)...)
+				if len(targets) > 0 {
+					abilityTriggered = g.dealMonsterDamage(*m, damageTaken, targets) || abilityTriggered
+				}
+			}
+			abilityTriggered = g.drainStamina(*m, agentsFighting) || abilityTriggered
+			abilityTriggered = g.disarm(*m, agentsFighting) || abilityTriggered
+		}
+	}
+	g.updateView()
+	return abilityTriggered
+}
+
+// defection returns a copy of every agent's defection record.
+func (g *Game) defection() map[commons.ID]state.Defector {
+	defection := make(map[commons.ID]state.Defector, len(g.state.AgentState))
+	for id, agentState := range g.state.AgentState {
+		defection[id] = agentState.Defector
+	}
+	return defection
+}
+
+// defectorsSince lists the agents whose defection record has changed, which is only ever by defecting in a fight.
+func (g *Game) defectorsSince(defection map[commons.ID]state.Defector) []commons.ID {
+	defectors := make([]commons.ID, 0)
+	for _, id := range commons.SortedKeys(g.state.AgentState) {
+		if g.state.AgentState[id].Defector != defection[id] {
+			defectors = append(defectors, id)
+		}
+	}
+	return defectors
+}
+
+func (g *Game) markFightDefectors(defectors []commons.ID) {
+	for _, id := range defectors {
+		agentState := g.state.AgentState[id]
+		agentState.Defector.SetFight(true)
+		g.state.AgentState[id] = agentState
+	}
+}
+
+// health returns the health of the monster and of every agent.
+func (g *Game) health() (uint, map[commons.ID]uint) {
+	hp := make(map[commons.ID]uint, len(g.state.AgentState))
+	for id, agentState := range g.state.AgentState {
+		hp[id] = agentState.Hp
+	}
+	return g.state.MonsterHealth(), hp
+}
+
+// damageSince works out the damage dealt since health was taken. An agent that has died lost all its health.
+func (g *Game) damageSince(monsterHealth uint, agentHealth map[commons.ID]uint) *Damage {
+	damage := &Damage{Monster: monsterHealth - g.state.MonsterHealth(), Agents: make(map[commons.ID]uint)}
+	for id, hp := range agentHealth {
+		if remaining := g.state.AgentState[id].Hp; remaining < hp {
+			damage.Agents[id] = hp - remaining
+		}
+	}
+	return damage
+}
+
+// recordRound adds the round to the level's fight history, given the damage dealt in it and the health of
+// the agents alive at its start.
+func (g *Game) recordRound(round uint, result decision.FightResult, damage *Damage, agentHealth map[commons.ID]uint) {
+	var deaths []commons.ID
+	for _, id := range commons.SortedKeys(agentHealth) {
+		if _, ok := g.state.AgentState[id]; !ok {
+			deaths = append(deaths, id)
+		}
+	}
+	g.state.RecordRound(state.RoundRecord{
+		Round:         round,
+		Choices:       commons.MapToImmutable(result.Choices),
+		Targets:       commons.MapToImmutable(result.Targets),
+		AttackSum:     result.AttackSum,
+		ShieldSum:     result.ShieldSum,
+		Damage:        commons.MapToImmutable(damage.Agents),
+		Deaths:        commons.ListToImmutableList(deaths),
+		MonsterHealth: g.state.MonsterHealth(),
+	})
+	g.updateView()
+}
+
+/*
+	Monster Helpers
+*/
+
+func (g *Game) monsters() monster.Catalogue {
+	if g.config.Monsters == nil {
+		return monster.DefaultCatalogue()
+	}
+	return g.config.Monsters
+}
+
+// spawnMonsters draws the monsters of a level from the catalogue, sharing the level's monster health and attack
+// evenly between them.
+func (g *Game) spawnMonsters(level uint, health uint, attack uint) state.Monsters {
+	n := g.config.MonstersPerLevel
+	if n == 0 {
+		n = 1
+	}
+	monsters := make(state.Monsters, n)
+	for i := range monsters {
+		monsters[i] = state.MonsterState{
+			Monster: g.monsters().Draw(g.rng, level),
+			Health:  share(health, n, uint(i)),
+			Attack:  share(attack, n, uint(i)),
+		}
+	}
+	return monsters
+}
+
+// share returns the i-th of n even shares of total, the first shares taking one more each for any remainder.
+func share(total uint, n uint, i uint) uint {
+	s := total / n
+	if i < total%n {
+		s++
+	}
+	return s
+}
+
+// enrage grows the attack of every monster still alive by EnragePercentage, and by at least one.
+func (g *Game) enrage() {
+	for i := range g.state.Monsters {
+		m := &g.state.Monsters[i]
+		if m.Health == 0 {
+			continue
+		}
+		enrage := m.Attack * g.config.EnragePercentage / 100
+		if enrage == 0 {
+			enrage = 1
+		}
+		m.Attack += enrage
+	}
+	g.updateView()
+}
+
+// monsterTriggers reports whether the monster's chance-based ability goes off this round.
+func (g *Game) monsterTriggers(m state.MonsterState, ability monster.Ability) bool {
+	return m.Ability == ability && uint(g.rng.Intn(100)) < m.Strength
+}
+
+// silenced reports whether any of the monsters still alive silences this round's fight discussion.
+func (g *Game) silenced() bool {
+	silenced := false
+	for _, m := range g.state.Monsters {
+		if m.Health > 0 && g.monsterTriggers(m, monster.Silence) {
+			silenced = true
+		}
+	}
+	return silenced
+}
+
+// alive filters out the agents that have died during the round.
+func (g *Game) alive(ids []commons.ID) []commons.ID {
+	living := make([]commons.ID, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := g.agents[id]; ok {
+			living = append(living, id)
+		}
+	}
+	return living
+}
+
+// dealMonsterDamage splits the damage between the fighting agents by the game's damage model, except for the
+// share a TargetStrongest monster aims at the agent with the highest total attack.
+func (g *Game) dealMonsterDamage(m state.MonsterState, damage uint, targets []fight.Target) bool {
+	if m.Ability != monster.TargetStrongest {
+		fight.DealDamage(g.damageModel().Split(damage, targets, g.rng), g.agents, g.state)
+		return false
+	}
+
+	strongest := targets[0].ID
+	for _, target := range targets[1:] {
+		id := target.ID
+		agentState, strongestState := g.state.AgentState[id], g.state.AgentState[strongest]
+		if agentState.TotalAttack() > strongestState.TotalAttack() ||
+			(agentState.TotalAttack() == strongestState.TotalAttack() && id < strongest) {
+			strongest = id
+		}
+	}
+	targeted := damage * m.Strength / 100
+	fight.DealDamage(g.damageModel().Split(damage-targeted, targets, g.rng), g.agents, g.state)
+	if targeted > 0 {
+		fight.DealDamage(map[commons.ID]uint{strongest: targeted}, g.agents, g.state)
+	}
+	return targeted > 0
+}
+
+func (g *Game) damageModel() fight.DamageModel {
+	return fight.NewDamageModel(g.state.DamageModel)
+}
+
+// targets describes the agents that took action, in ID order, for a damage model.
+func (g *Game) targets(agents []commons.ID, action decision.FightAction) []fight.Target {
+	targets := make([]fight.Target, 0, len(agents))
+	for _, id := range agents {
+		targets = append(targets, fight.Target{ID: id, Action: action, State: g.state.AgentState[id]})
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].ID < targets[j].ID })
+	return targets
+}
+
+func (g *Game) drainStamina(m state.MonsterState, agentsFighting []commons.ID) bool {
+	if m.Ability != monster.StaminaDrain {
+		return false
+	}
+	drained := false
+	for _, id := range g.alive(agentsFighting) {
+		drain := g.state.AgentState[id].Stamina * m.Strength / 100
+		g.state.SpendStamina(id, drain, state.CauseAbility)
+		drained = drained || drain > 0
+	}
+	return drained
+}
+
+// regenerate gives every living agent the stamina it recovers at the end of a fight round or level.
+func (g *Game) regenerate(stamina uint) {
+	for _, id := range commons.SortedKeys(g.state.AgentState) {
+		g.state.GainStamina(id, stamina, state.CauseRegen)
+	}
+	g.updateView()
+}
+
+// disarm destroys the weapon in use by a random fighting agent, or their shield if they have no weapon equipped.
+func (g *Game) disarm(m state.MonsterState, agentsFighting []commons.ID) bool {
+	living := g.alive(agentsFighting)
+	if len(living) == 0 || !g.monsterTriggers(m, monster.Disarm) {
+		return false
+	}
+	id := living[g.rng.Intn(len(living))]
+	agentState := g.state.AgentState[id]
+	var destroyed commons.ItemID
+	if agentState.HasItem(commons.Weapon, agentState.WeaponInUse) {
+		destroyed = agentState.WeaponInUse
+		g.state.TakeItem(id, commons.Weapon, destroyed)
+	} else if agentState.HasItem(commons.Shield, agentState.ShieldInUse) {
+		destroyed = agentState.ShieldInUse
+		g.state.TakeItem(id, commons.Shield, destroyed)
+	} else {
+		return false
+	}
+	logging.Log(logging.Info, logging.LogField{"agentID": id, "item": destroyed}, "Monster destroyed an item")
+	return true
+}
+
+/*
+	Hp Pool Helpers
+*/
+
+func (g *Game) checkHpPool() bool {
+	if monsterHealth := g.state.MonsterHealth(); g.state.HpPool >= monsterHealth {
+		logging.Log(logging.Info, logging.LogField{
+			"Original HP Pool":  g.state.HpPool,
+			"Monster Health":    monsterHealth,
+			"HP Pool Remaining": g.state.HpPool - monsterHealth,
+		}, fmt.Sprintf("Skipping level %d through HP Pool", g.state.CurrentLevel))
+
+		g.state.HpPool -= monsterHealth
+		for i := range g.state.Monsters {
+			g.state.Monsters[i].Health = 0
+		}
+		return true
+	}
+	return false
+}
+
+func (g *Game) generateLootPool(numAgents int, currentLevel uint) *state.LootPool {
+	makeItems := func() *commons.ImmutableList[state.Item] {
+		nItems := g.rng.Intn(numAgents) / 10
+		items := make([]state.Item, nItems)
+		for i := 0; i < nItems; i++ {
+			items[i] = *state.NewItem(commons.NewSeededID(g.rng), currentLevel*uint(g.rng.Intn(3)+1))
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Value() > items[j].Value()
+		})
+		return commons.NewImmutableList(items)
+	}
+
+	return state.NewLootPool(
+		makeItems(),
+		makeItems(),
+		makeItems(),
+		makeItems(),
+	)
+}
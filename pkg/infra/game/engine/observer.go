@@ -0,0 +1,266 @@
+package engine
+
+import (
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/message"
+	"infra/game/monster"
+	"infra/game/stage/election"
+	"infra/game/state"
+	"infra/logging"
+
+	"github.com/benbjohnson/immutable"
+)
+
+// Observer is told what happens in a game as it happens, e.g. to log it, analyse it or check assertions.
+// Observers are called one after another on the goroutine playing the game, which waits for them.
+// Payloads hold immutable collections or copies the engine no longer refers to, so observers may keep them.
+type Observer interface {
+	OnLevelStart(LevelStart)
+	OnElection(Election)
+	OnConfidenceVote(ConfidenceVote)
+	OnFightRound(FightRound)
+	OnAgentDeath(AgentDeath)
+	OnLootAllocated(LootAllocated)
+	OnTradeExecuted(TradeExecuted)
+	OnHpPoolDonation(HpPoolDonation)
+	OnPolicyBreach(PolicyBreach)
+	OnLeaderChanged(LeaderChanged)
+	OnLevelEnd(LevelEnd)
+	OnGameEnd(GameEnd)
+}
+
+// BaseObserver ignores everything. Embed it to implement only the Observer methods you need.
+type BaseObserver struct{}
+
+func (BaseObserver) OnLevelStart(LevelStart)         {}
+func (BaseObserver) OnElection(Election)             {}
+func (BaseObserver) OnConfidenceVote(ConfidenceVote) {}
+func (BaseObserver) OnFightRound(FightRound)         {}
+func (BaseObserver) OnAgentDeath(AgentDeath)         {}
+func (BaseObserver) OnLootAllocated(LootAllocated)   {}
+func (BaseObserver) OnTradeExecuted(TradeExecuted)   {}
+func (BaseObserver) OnHpPoolDonation(HpPoolDonation) {}
+func (BaseObserver) OnPolicyBreach(PolicyBreach)     {}
+func (BaseObserver) OnLeaderChanged(LeaderChanged)   {}
+func (BaseObserver) OnLevelEnd(LevelEnd)             {}
+func (BaseObserver) OnGameEnd(GameEnd)               {}
+
+// LevelStart is the game as a level begins, before the leader is elected or confirmed.
+type LevelStart struct {
+	Level  uint
+	Leader commons.ID
+	HpPool uint
+	// MonsterHealth and MonsterAttack are the combined health and attack of the level's Monsters
+	MonsterHealth uint
+	MonsterAttack uint
+	Monsters      immutable.List[state.MonsterState]
+	Agents        immutable.Map[commons.ID, state.AgentState]
+}
+
+type Election struct {
+	Level     uint
+	Winner    commons.ID
+	Team      string
+	Manifesto decision.Manifesto
+	// RunnerUp takes over if the winner dies or is recalled and its manifesto names no living deputy, as the
+	// succession and recall rules decide
+	RunnerUp commons.ID
+	// NoConfidence is set when the election was held because the leader lost a confidence vote, and Succession
+	// when it died with no one to take over
+	NoConfidence bool
+	Succession   bool
+	// Strategy is the voting rule the votes were counted with, and Candidates every agent that stood
+	Strategy   decision.VotingStrategy
+	Candidates immutable.Map[commons.ID, Candidate]
+	// Ballots are the ballots cast by each voter, and RunoffBallots those cast in the second round of a runoff
+	Ballots       immutable.Map[commons.ID, immutable.List[commons.ID]]
+	RunoffBallots immutable.Map[commons.ID, immutable.List[commons.ID]]
+	// Counts are the rounds of counting, in order, including the round that chose a runoff's finalists
+	Counts commons.ImmutableList[CountRound]
+}
+
+// Candidate is an agent that stood in an election.
+type Candidate struct {
+	Team      string
+	Manifesto decision.Manifesto
+}
+
+// CountRound is a round of counting the votes of an election: the score the voting rule gave each candidate it
+// counted, and the candidates it eliminated.
+type CountRound struct {
+	Scores     immutable.Map[commons.ID, float64]
+	Eliminated immutable.List[commons.ID]
+}
+
+type ConfidenceVote struct {
+	Level   uint
+	Leader  commons.ID
+	For     uint
+	Against uint
+	Abstain uint
+	// Threshold is the percentage of the counted votes against the leader needed to recall it, and Quorate
+	// is set when enough votes were counted for the vote to stand
+	Threshold uint
+	Quorate   bool
+	// Ousted is set when the vote went against the leader, in which case Deputy, its deputy or the runner-up,
+	// takes over, or an Election follows if it is empty
+	Ousted bool
+	Deputy commons.ID
+}
+
+type FightRound struct {
+	Level uint
+	Round uint
+	// Actions are the actions the agents chose; agents without the stamina for their choice cowered instead
+	Actions         immutable.Map[commons.ID, decision.FightAction]
+	AttackingAgents immutable.List[commons.ID]
+	ShieldingAgents immutable.List[commons.ID]
+	CoweringAgents  immutable.List[commons.ID]
+	// Targets are the monsters the agents that attacked or defended fought, and AttackSums and ShieldSums
+	// the total attack and defense aimed at each monster
+	Targets       immutable.Map[commons.ID, decision.MonsterIdx]
+	AttackSums    immutable.List[uint]
+	ShieldSums    immutable.List[uint]
+	AttackSum     uint
+	ShieldSum     uint
+	MonsterHealth uint
+	// MonsterAttack is the combined attack of the monsters that were alive when the round began
+	MonsterAttack uint
+	// Monsters are the level's monsters at the end of the round
+	Monsters immutable.List[state.MonsterState]
+	// Ability is the ability of the first monster still alive, and AbilityTriggered whether any monster's
+	// ability had an effect this round
+	Ability          monster.Ability
+	AbilityTriggered bool
+	AgentsRemaining  uint
+}
+
+// DeathCause is how an agent died.
+type DeathCause string
+
+const (
+	KilledInFight  DeathCause = "fight"
+	DonatedAllToHp DeathCause = "donation"
+)
+
+type AgentDeath struct {
+	Level uint
+	ID    commons.ID
+	Team  string
+	Cause DeathCause
+}
+
+type LootAllocated struct {
+	Level      uint
+	Allocation immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]]
+	// Discussion is the loot discussion, which is only known when the game is not being replayed
+	Discussion logging.LootStage
+}
+
+// TradeExecuted is a negotiation both parties agreed to, after which they swapped the offered items.
+type TradeExecuted struct {
+	Level uint
+	Trade message.TradeNegotiation
+	// Tax is the health each party paid into the HP pool under the leader's trade tax
+	Tax immutable.Map[commons.ID, uint]
+}
+
+type HpPoolDonation struct {
+	Level uint
+	// Donations holds the health each agent gave up, which is at most the health it had
+	Donations immutable.Map[commons.ID, uint]
+	OldPool   uint
+	NewPool   uint
+}
+
+// PolicyBreach is a decision of the leader that went against the policies of its manifesto.
+// The engine enforces the policies regardless, where it can.
+type PolicyBreach struct {
+	Level  uint
+	Leader commons.ID
+	Policy decision.Policy
+	// Agents are the agents the decision concerned, e.g. those the leader let cower or passed over for loot
+	Agents immutable.List[commons.ID]
+}
+
+// LeaderChanged is a leader leaving office before the end of its term.
+type LeaderChanged struct {
+	Level    uint
+	Previous commons.ID
+	// Leader is the agent that took over, which is empty if none did straight away: until the snap election
+	// at the end of the stage, or for the rest of the level under config.SuccessionLeaderless
+	Leader    commons.ID
+	Reason    ChangeReason
+	Successor Successor
+}
+
+type LevelEnd struct {
+	Level uint
+	// Lost is set when the game was lost during the level, which then ends straight after its fight
+	Lost                 bool
+	SkippedThroughHpPool bool
+	// Stalemate is the stalemate rule applied if the fight reached the round limit, empty otherwise
+	Stalemate string
+	// AgentLogs are the properties the agents reported at the end of the level, if it was completed
+	AgentLogs map[commons.ID]logging.AgentLog
+	// Deltas are the changes made to each agent's state during the level, including those of the agents that died
+	Deltas map[commons.ID]state.Delta
+}
+
+type GameEnd struct {
+	Level     uint
+	Outcome   logging.Outcome
+	Survivors uint
+}
+
+// Observe adds an observer to the game. It must only be called between levels.
+func (g *Game) Observe(o Observer) {
+	g.observers = append(g.observers, o)
+}
+
+func (g *Game) notify(event func(Observer)) {
+	for _, o := range g.observers {
+		event(o)
+	}
+}
+
+// agentStates returns an immutable copy of the agents' states.
+func (g *Game) agentStates() immutable.Map[commons.ID, state.AgentState] {
+	return commons.MapToImmutable(g.state.AgentState)
+}
+
+// teams returns the team name of every living agent.
+func (g *Game) teams() map[commons.ID]string {
+	teams := make(map[commons.ID]string, len(g.agents))
+	for id, a := range g.agents {
+		teams[id] = a.BaseAgent.Name()
+	}
+	return teams
+}
+
+// reportDeaths tells the observers about the agents in teams that are no longer alive.
+func (g *Game) reportDeaths(teams map[commons.ID]string, cause DeathCause) {
+	for _, id := range commons.SortedKeys(teams) {
+		if _, ok := g.agents[id]; !ok {
+			death := AgentDeath{Level: g.state.CurrentLevel, ID: id, Team: teams[id], Cause: cause}
+			g.notify(func(o Observer) { o.OnAgentDeath(death) })
+		}
+	}
+}
+
+func immutableBallots(ballots map[commons.ID]decision.Ballot) immutable.Map[commons.ID, immutable.List[commons.ID]] {
+	lists := make(map[commons.ID]immutable.List[commons.ID], len(ballots))
+	for voter, ballot := range ballots {
+		lists[voter] = commons.ListToImmutableList(ballot)
+	}
+	return commons.MapToImmutable(lists)
+}
+
+func countRounds(rounds []election.Round) commons.ImmutableList[CountRound] {
+	counts := make([]CountRound, len(rounds))
+	for i, round := range rounds {
+		counts[i] = CountRound{Scores: commons.MapToImmutable(round.Scores), Eliminated: commons.ListToImmutableList(round.Eliminated)}
+	}
+	return *commons.NewImmutableList(counts)
+}
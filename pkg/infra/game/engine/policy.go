@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/stage/fight"
+	"infra/game/stage/hppool"
+	"infra/game/state"
+	"infra/logging"
+)
+
+// policies returns the policies of the leader's manifesto, which the engine enforces for the leader's term.
+func (g *Game) policies() decision.Policies {
+	return g.state.LeaderManifesto.Policies()
+}
+
+// reportBreaches tells the observers about the leader's breaches of its policies.
+func (g *Game) reportBreaches(breaches []decision.Breach) {
+	for _, b := range breaches {
+		breach := PolicyBreach{
+			Level:  g.state.CurrentLevel,
+			Leader: g.state.CurrentLeader,
+			Policy: b.Policy,
+			Agents: commons.ListToImmutableList(b.Agents),
+		}
+		logging.Log(logging.Info, logging.LogField{
+			"currLevel": breach.Level,
+			"leader":    breach.Leader,
+			"policy":    breach.Policy,
+			"agents":    b.Agents,
+		}, "Leader breached a policy")
+		g.notify(func(o Observer) { o.OnPolicyBreach(breach) })
+	}
+}
+
+// capCowering makes agents that chose to cower defend instead, so that no more cower than the cower cap allows.
+func (g *Game) capCowering(choices map[commons.ID]decision.FightAction) {
+	if defending := fight.CapCowering(*g.state, choices, g.policies()); len(defending) > 0 {
+		logging.Log(logging.Debug, logging.LogField{
+			"currLevel": g.state.CurrentLevel,
+			"agents":    defending,
+		}, "Cower cap made agents defend")
+	}
+}
+
+// forfeitLoot takes the level's defectors out of the loot allocation, if the policies punish them that way.
+func (g *Game) forfeitLoot(allocation map[commons.ID][]commons.ItemID, defectors map[commons.ID]struct{}) map[commons.ID][]commons.ItemID {
+	if g.policies().DefectorPunishment != decision.ForfeitLoot {
+		return allocation
+	}
+	kept := make(map[commons.ID][]commons.ItemID, len(allocation))
+	for id, items := range allocation {
+		if _, ok := defectors[id]; !ok {
+			kept[id] = items
+		}
+	}
+	return kept
+}
+
+// fineDefectors takes the fine of the policies off the health of the level's defectors, into the HP pool.
+func (g *Game) fineDefectors(defectors map[commons.ID]struct{}) {
+	policies := g.policies()
+	if policies.DefectorPunishment != decision.FineDefectors {
+		return
+	}
+	for _, id := range commons.SortedKeys(defectors) {
+		g.state.Levy(id, decision.Share(g.state.AgentState[id].Hp, policies.DefectorFine), state.CauseFine)
+	}
+}
+
+// mandateDonations raises the donations to the mandatory share of the agents' health. The leader falling short of it
+// is a breach of its policies.
+func (g *Game) mandateDonations(donations map[commons.ID]uint) map[commons.ID]uint {
+	mandated, raised := hppool.Mandate(g.state, donations, g.policies().HpPoolDonation)
+	for _, id := range raised {
+		if id == g.state.CurrentLeader {
+			g.reportBreaches([]decision.Breach{{Policy: decision.HpPoolDonationPolicy, Agents: []commons.ID{id}}})
+		}
+	}
+	return mandated
+}
@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/state"
+)
+
+// replayer feeds the decisions in an event log back to the engine and checks its outcomes against the log.
+type replayer struct {
+	decoder *json.Decoder
+}
+
+// divergence unwinds the level being replayed once the engine departs from the log.
+type divergence struct {
+	err error
+}
+
+// Replay plays a game again from its event log. The agents' decisions are taken from the log instead of
+// their strategies, which are never called, and the engine must reach the outcomes in the log: the damage
+// dealt in every fight round and the state at the end of every level. It returns the number of levels
+// replayed, and an error describing where the replay diverged from the log, if it did.
+func Replay(r io.Reader) (levels uint, err error) {
+	rep := &replayer{decoder: json.NewDecoder(r)}
+	var start Event
+	if err := rep.decoder.Decode(&start); err != nil {
+		return 0, fmt.Errorf("reading event log: %w", err)
+	}
+	if start.Kind != StartEvent || start.Start == nil {
+		return 0, fmt.Errorf("event log starts with a %s event, not a %s event", start.Kind, StartEvent)
+	}
+
+	source := commons.RestoreSource(start.Start.Source)
+	g := &Game{
+		config:   start.Start.Config,
+		state:    &start.Start.State,
+		agents:   make(map[commons.ID]agent.Agent, len(start.Start.Agents)),
+		view:     &state.View{},
+		rng:      rand.New(source),
+		source:   source,
+		termLeft: start.Start.TermLeft,
+		replay:   rep,
+		logger:   &gameLogger{},
+	}
+	g.Observe(g.logger)
+	for id, name := range start.Start.Agents {
+		g.agents[id] = agent.Agent{BaseAgent: agent.NewBaseAgent(nil, id, name, g.view, commons.NewSource(0))}
+	}
+	g.connectAgents()
+	g.updateView()
+
+	defer func() {
+		if p := recover(); p != nil {
+			d, ok := p.(divergence)
+			if !ok {
+				panic(p)
+			}
+			err = d.err
+		}
+	}()
+	for !g.finished {
+		g.StepLevel()
+		levels++
+	}
+	if rep.decoder.More() {
+		return levels, errors.New("event log continues after the game ended")
+	}
+	return levels, nil
+}
+
+func (r *replayer) read(g *Game, kind EventKind, round uint) Event {
+	var e Event
+	if err := r.decoder.Decode(&e); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errors.New("event log ended")
+		}
+		r.diverge(g, "reading %s event: %v", kind, err)
+	}
+	if e.Kind != kind || e.Level != g.state.CurrentLevel || e.Round != round {
+		r.diverge(g, "expected %s event for round %d, log has %s event for level %d round %d", kind, round, e.Kind, e.Level, e.Round)
+	}
+	return e
+}
+
+// next returns the decision the engine asks for, with the game's random source moved on past any values
+// drawn while the agents' decision was resolved.
+func (r *replayer) next(g *Game, kind EventKind, round uint) Event {
+	e := r.read(g, kind, round)
+	if err := g.source.AdvanceTo(e.Draws); err != nil {
+		r.diverge(g, "before %s event: %v", kind, err)
+	}
+	return e
+}
+
+func (r *replayer) check(g *Game, got Event) {
+	want := r.read(g, got.Kind, got.Round)
+	got.Level = g.state.CurrentLevel
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		r.diverge(g, "encoding %s event: %v", got.Kind, err)
+	}
+	wantJSON, _ := json.Marshal(want)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		r.diverge(g, "%s event differs from the log\n got: %s\nwant: %s", got.Kind, gotJSON, wantJSON)
+	}
+}
+
+func (r *replayer) diverge(g *Game, format string, args ...any) {
+	panic(divergence{err: fmt.Errorf("level %d: %s", g.state.CurrentLevel, fmt.Sprintf(format, args...))})
+}
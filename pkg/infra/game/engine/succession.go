@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"infra/config"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/logging"
+)
+
+// ChangeReason is why a leader left office before the end of its term.
+type ChangeReason string
+
+const (
+	LeaderDied     ChangeReason = "died"
+	LeaderRecalled ChangeReason = "recalled"
+)
+
+// Successor is how the agent that took over from a leader was chosen.
+type Successor string
+
+const (
+	// SuccessorDeputy is the deputy named in the leader's manifesto, and SuccessorRunnerUp the runner-up of the
+	// election that chose the leader. Either serves out the term on the leader's manifesto.
+	SuccessorDeputy   Successor = "deputy"
+	SuccessorRunnerUp Successor = "runner_up"
+	// SuccessorElection is the winner of a snap election.
+	SuccessorElection Successor = "election"
+	// SuccessorNone leaves the agents without a leader, under config.SuccessionLeaderless.
+	SuccessorNone Successor = "none"
+)
+
+// successor returns the living agent next in line to take over from the leader: its deputy or, failing that and if
+// runnerUp is set, the runner-up of the election that chose it. It returns an empty ID if there is no one, in which
+// case a snap election is the way to a new leader.
+func (g *Game) successor(runnerUp bool) (commons.ID, Successor) {
+	if _, ok := g.agents[g.state.Deputy]; ok && g.state.Deputy != g.state.CurrentLeader {
+		return g.state.Deputy, SuccessorDeputy
+	}
+	if _, ok := g.agents[g.state.RunnerUp]; ok && runnerUp && g.state.RunnerUp != g.state.CurrentLeader {
+		return g.state.RunnerUp, SuccessorRunnerUp
+	}
+	return "", SuccessorElection
+}
+
+// takeOver hands the rest of the leader's term to successor, which leads on the leader's manifesto.
+func (g *Game) takeOver(successor commons.ID) {
+	g.state.CurrentLeader = successor
+	if g.state.Deputy == successor {
+		g.state.Deputy = ""
+	}
+	if g.state.RunnerUp == successor {
+		g.state.RunnerUp = ""
+	}
+	g.updateView()
+}
+
+// succeed replaces a leader that died during its term with its deputy or, if it has none alive, as the succession
+// rule decides. Without a successor the agents have no leader, and no manifesto, until a snap election is held
+// by fillVacancy or the next level begins with an election.
+func (g *Game) succeed() {
+	leader := g.state.CurrentLeader
+	if _, alive := g.agents[leader]; alive || leader == "" {
+		return
+	}
+	rule := g.config.Succession
+	successor, via := g.successor(rule == "" || rule == config.SuccessionRunnerUp)
+	if successor != "" {
+		g.takeOver(successor)
+	} else {
+		g.state.CurrentLeader, g.state.LeaderManifesto = "", decision.Manifesto{}
+		g.state.Deputy, g.state.RunnerUp = "", ""
+		g.updateView()
+		if rule == config.SuccessionLeaderless {
+			via = SuccessorNone
+		}
+	}
+	g.changeLeader(leader, LeaderDied, via)
+}
+
+// fillVacancy holds the snap election called for by the death of a leader with no one to take over, once the
+// stage it died in is over.
+func (g *Game) fillVacancy() {
+	if g.state.CurrentLeader != "" || g.config.Succession == config.SuccessionLeaderless {
+		return
+	}
+	g.termLeft = g.runElection(successionElection)
+}
+
+// changeLeader tells the observers that previous left office for reason, and that the current leader took over.
+func (g *Game) changeLeader(previous commons.ID, reason ChangeReason, via Successor) {
+	changed := LeaderChanged{
+		Level:     g.state.CurrentLevel,
+		Previous:  previous,
+		Leader:    g.state.CurrentLeader,
+		Reason:    reason,
+		Successor: via,
+	}
+	logging.Log(logging.Info, logging.LogField{
+		"currLevel": changed.Level,
+		"previous":  changed.Previous,
+		"leader":    changed.Leader,
+		"reason":    changed.Reason,
+		"successor": changed.Successor,
+	}, "Leader changed")
+	g.notify(func(o Observer) { o.OnLeaderChanged(changed) })
+}
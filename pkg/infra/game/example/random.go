@@ -1,6 +1,7 @@
 package example
 
 import (
+	"encoding/json"
 	"infra/game/agent"
 	"infra/game/commons"
 	"infra/game/decision"
@@ -26,7 +27,7 @@ func (r *RandomAgent) FightResolution(
 	builder := immutable.NewMapBuilder[commons.ID, decision.FightAction](nil)
 	for _, id := range commons.ImmutableMapKeys(view.AgentState()) {
 		var fightAction decision.FightAction
-		switch rand.Intn(3) {
+		switch agent.Rand().Intn(3) {
 		case 0:
 			fightAction = decision.Attack
 		case 1:
@@ -47,31 +48,32 @@ func (r *RandomAgent) LootActionNoProposal(baseAgent agent.BaseAgent) immutable.
 	staminaPotions := loot.StaminaPotions().Iterator()
 
 	builder := immutable.NewSortedMapBuilder[commons.ItemID, struct{}](nil)
+	rng := baseAgent.Rand()
 
 	for !weapons.Done() {
 		weapon, _ := weapons.Next()
-		if rand.Int()%2 == 0 {
+		if rng.Int()%2 == 0 {
 			builder.Set(weapon.Id(), struct{}{})
 		}
 	}
 
 	for !shields.Done() {
 		shield, _ := shields.Next()
-		if rand.Int()%2 == 0 {
+		if rng.Int()%2 == 0 {
 			builder.Set(shield.Id(), struct{}{})
 		}
 	}
 
 	for !hpPotions.Done() {
 		pot, _ := hpPotions.Next()
-		if rand.Int()%2 == 0 {
+		if rng.Int()%2 == 0 {
 			builder.Set(pot.Id(), struct{}{})
 		}
 	}
 
 	for !staminaPotions.Done() {
 		pot, _ := staminaPotions.Next()
-		if rand.Int()%2 == 0 {
+		if rng.Int()%2 == 0 {
 			builder.Set(pot.Id(), struct{}{})
 		}
 	}
@@ -87,8 +89,8 @@ func (r *RandomAgent) LootAction(
 	return proposedLoot
 }
 
-func (r *RandomAgent) FightActionNoProposal(_ agent.BaseAgent) decision.FightAction {
-	fight := rand.Intn(3)
+func (r *RandomAgent) FightActionNoProposal(baseAgent agent.BaseAgent) decision.FightAction {
+	fight := baseAgent.Rand().Intn(3)
 	switch fight {
 	case 0:
 		return decision.Cower
@@ -107,15 +109,53 @@ func (r *RandomAgent) FightAction(
 	return r.FightActionNoProposal(baseAgent)
 }
 
-func (r *RandomAgent) HandleLootInformation(m message.TaggedInformMessage[message.LootInform], _ agent.BaseAgent) {
+func (r *RandomAgent) HandleLootInformation(m message.TaggedInformMessage[message.LootInform], baseAgent agent.BaseAgent) {
+	if _, ok := m.Message().(*message.StartLoot); !ok {
+		return
+	}
+
+	// tell the leader what we are short of, and sometimes suggest how to share the loot
+	view := baseAgent.View()
+	if needs := message.NeedsOf(baseAgent.AgentState()); len(needs.Needs) > 0 && view.CurrentLeader() != baseAgent.ID() {
+		_ = baseAgent.SendBlockingMessage(view.CurrentLeader(), needs)
+	}
+
+	makesProposal := baseAgent.Rand().Intn(100)
+	if makesProposal > 80 {
+		rules := make([]proposal.Rule[decision.LootAction], 0)
+
+		rules = append(rules, *proposal.NewRule[decision.LootAction](decision.HealthPotion,
+			proposal.NewComparativeCondition(proposal.Health, proposal.LessThan, state.LowHealth),
+		))
+
+		rules = append(rules, *proposal.NewRule[decision.LootAction](decision.StaminaPotion,
+			proposal.NewComparativeCondition(proposal.Stamina, proposal.LessThan, state.LowStamina),
+		))
+
+		rules = append(rules, *proposal.NewRule[decision.LootAction](decision.Weapon,
+			proposal.NewComparativeCondition(proposal.TotalAttack, proposal.LessThan, 50),
+		))
+
+		rules = append(rules, *proposal.NewRule[decision.LootAction](decision.Shield,
+			proposal.NewComparativeCondition(proposal.TotalDefence, proposal.LessThan, 50),
+		))
+
+		prop := *commons.NewImmutableList(rules)
+		_ = baseAgent.SendLootProposalToLeader(prop)
+	}
 }
 
-func (r *RandomAgent) HandleLootRequest(m message.TaggedRequestMessage[message.LootRequest]) message.LootInform {
-	return nil
+func (r *RandomAgent) HandleLootRequest(m message.TaggedRequestMessage[message.LootRequest], baseAgent agent.BaseAgent) message.LootInform {
+	switch m.Message().(type) {
+	case message.LootNeedsRequest:
+		return message.NeedsOf(baseAgent.AgentState())
+	default:
+		return nil
+	}
 }
 
-func (r *RandomAgent) HandleLootProposal(_ message.Proposal[decision.LootAction], _ agent.BaseAgent) decision.Intent {
-	switch rand.Intn(3) {
+func (r *RandomAgent) HandleLootProposal(_ message.Proposal[decision.LootAction], baseAgent agent.BaseAgent) decision.Intent {
+	switch baseAgent.Rand().Intn(3) {
 	case 0:
 		return decision.Positive
 	case 1:
@@ -125,8 +165,8 @@ func (r *RandomAgent) HandleLootProposal(_ message.Proposal[decision.LootAction]
 	}
 }
 
-func (r *RandomAgent) HandleLootProposalRequest(_ message.Proposal[decision.LootAction], _ agent.BaseAgent) bool {
-	switch rand.Intn(2) {
+func (r *RandomAgent) HandleLootProposalRequest(_ message.Proposal[decision.LootAction], baseAgent agent.BaseAgent) bool {
+	switch baseAgent.Rand().Intn(2) {
 	case 0:
 		return true
 	default:
@@ -143,13 +183,13 @@ func (r *RandomAgent) LootAllocation(
 	view := baseAgent.View()
 	ids := commons.ImmutableMapKeys(view.AgentState())
 	iterator := baseAgent.Loot().Weapons().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(baseAgent.Rand(), iterator, ids, lootAllocation)
 	iterator = baseAgent.Loot().Shields().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(baseAgent.Rand(), iterator, ids, lootAllocation)
 	iterator = baseAgent.Loot().HpPotions().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(baseAgent.Rand(), iterator, ids, lootAllocation)
 	iterator = baseAgent.Loot().StaminaPotions().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(baseAgent.Rand(), iterator, ids, lootAllocation)
 	mMapped := make(map[commons.ID]immutable.SortedMap[commons.ItemID, struct{}])
 	for id, itemIDS := range lootAllocation {
 		mMapped[id] = commons.ListToImmutableSortedSet(itemIDS)
@@ -157,10 +197,10 @@ func (r *RandomAgent) LootAllocation(
 	return commons.MapToImmutable(mMapped)
 }
 
-func allocateRandomly(iterator commons.Iterator[state.Item], ids []commons.ID, lootAllocation map[commons.ID][]commons.ItemID) {
+func allocateRandomly(rng *rand.Rand, iterator commons.Iterator[state.Item], ids []commons.ID, lootAllocation map[commons.ID][]commons.ItemID) {
 	for !iterator.Done() {
 		next, _ := iterator.Next()
-		toBeAllocated := ids[rand.Intn(len(ids))]
+		toBeAllocated := ids[rng.Intn(len(ids))]
 		if l, ok := lootAllocation[toBeAllocated]; ok {
 			l = append(l, next.Id())
 			lootAllocation[toBeAllocated] = l
@@ -173,11 +213,11 @@ func allocateRandomly(iterator commons.Iterator[state.Item], ids []commons.ID, l
 }
 
 func (r *RandomAgent) DonateToHpPool(baseAgent agent.BaseAgent) uint {
-	return uint(rand.Intn(int(baseAgent.AgentState().Hp)))
+	return uint(baseAgent.Rand().Intn(int(baseAgent.AgentState().Hp)))
 }
 
 func (r *RandomAgent) UpdateInternalState(a agent.BaseAgent, _ *commons.ImmutableList[decision.ImmutableFightResult], _ *immutable.Map[decision.Intent, uint], log chan<- logging.AgentLog) {
-	r.bravery += rand.Intn(10)
+	r.bravery += a.Rand().Intn(10)
 	log <- logging.AgentLog{
 		Name: a.Name(),
 		ID:   a.ID(),
@@ -192,8 +232,8 @@ func (r *RandomAgent) CreateManifesto(_ agent.BaseAgent) *decision.Manifesto {
 	return manifesto
 }
 
-func (r *RandomAgent) HandleConfidencePoll(_ agent.BaseAgent) decision.Intent {
-	switch rand.Intn(3) {
+func (r *RandomAgent) HandleConfidencePoll(baseAgent agent.BaseAgent) decision.Intent {
+	switch baseAgent.Rand().Intn(3) {
 	case 0:
 		return decision.Abstain
 	case 1:
@@ -205,7 +245,7 @@ func (r *RandomAgent) HandleConfidencePoll(_ agent.BaseAgent) decision.Intent {
 
 func (r *RandomAgent) HandleFightInformation(_ message.TaggedInformMessage[message.FightInform], baseAgent agent.BaseAgent, _ *immutable.Map[commons.ID, decision.FightAction]) {
 	// baseAgent.Log(logging.Trace, logging.LogField{"bravery": r.bravery, "hp": baseAgent.AgentState().Hp}, "Cowering")
-	makesProposal := rand.Intn(100)
+	makesProposal := baseAgent.Rand().Intn(100)
 
 	if makesProposal > 80 {
 		rules := make([]proposal.Rule[decision.FightAction], 0)
@@ -254,9 +294,9 @@ func (r *RandomAgent) HandleElectionBallot(b agent.BaseAgent, _ *decision.Electi
 	// Randomly fill the ballot
 	var ballot decision.Ballot
 	numAliveAgents := len(aliveAgentIDs)
-	numCandidate := rand.Intn(numAliveAgents)
+	numCandidate := b.Rand().Intn(numAliveAgents)
 	for i := 0; i < numCandidate; i++ {
-		randomIdx := rand.Intn(numAliveAgents)
+		randomIdx := b.Rand().Intn(numAliveAgents)
 		randomCandidate := aliveAgentIDs[uint(randomIdx)]
 		ballot = append(ballot, randomCandidate)
 	}
@@ -264,8 +304,8 @@ func (r *RandomAgent) HandleElectionBallot(b agent.BaseAgent, _ *decision.Electi
 	return ballot
 }
 
-func (r *RandomAgent) HandleFightProposal(_ message.Proposal[decision.FightAction], _ agent.BaseAgent) decision.Intent {
-	intent := rand.Intn(2)
+func (r *RandomAgent) HandleFightProposal(_ message.Proposal[decision.FightAction], baseAgent agent.BaseAgent) decision.Intent {
+	intent := baseAgent.Rand().Intn(2)
 	if intent == 0 {
 		return decision.Positive
 	} else {
@@ -275,10 +315,10 @@ func (r *RandomAgent) HandleFightProposal(_ message.Proposal[decision.FightActio
 
 func (r *RandomAgent) HandleFightProposalRequest(
 	_ message.Proposal[decision.FightAction],
-	_ agent.BaseAgent,
+	baseAgent agent.BaseAgent,
 	_ *immutable.Map[commons.ID, decision.FightAction],
 ) bool {
-	switch rand.Intn(2) {
+	switch baseAgent.Rand().Intn(2) {
 	case 0:
 		return true
 	default:
@@ -300,6 +340,28 @@ func (r *RandomAgent) HandleTradeNegotiation(_ agent.BaseAgent, _ message.TradeI
 	return message.TradeRequest{}
 }
 
+type randomAgentState struct {
+	Bravery int
+}
+
+func (r *RandomAgent) SaveState() ([]byte, error) {
+	return json.Marshal(randomAgentState{Bravery: r.bravery})
+}
+
+func (r *RandomAgent) RestoreState(data []byte) error {
+	var saved randomAgentState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	r.bravery = saved.Bravery
+	return nil
+}
+
+// Init draws the agent's starting bravery from its seeded source.
+func (r *RandomAgent) Init(baseAgent agent.BaseAgent) {
+	r.bravery = baseAgent.Rand().Intn(5)
+}
+
 func NewRandomAgent() agent.Strategy {
-	return &RandomAgent{bravery: rand.Intn(5)}
+	return &RandomAgent{}
 }
@@ -3,28 +3,26 @@ package math
 import (
 	"math"
 	"math/rand"
-	"time"
 
 	"infra/config"
 )
 
-// Enemy Resilience Modifier
-func CalculateDelta() float64 {
+// Enemy Resilience Modifier, drawn from the game's random source
+func CalculateDelta(rng *rand.Rand) float64 {
 	min := 0.8
 	max := 1.2
-	rand.Seed(time.Now().UnixNano())
-	return min + rand.Float64()*(max-min)
+	return min + rng.Float64()*(max-min)
 }
 
 // X, the monster’s resilience
-func CalculateMonsterHealth(nAgent uint, stamina uint, nLevel uint, currentLevel uint) uint {
-	delta := CalculateDelta()
+func CalculateMonsterHealth(rng *rand.Rand, nAgent uint, stamina uint, nLevel uint, currentLevel uint) uint {
+	delta := CalculateDelta(rng)
 	return uint(math.Ceil((float64(nAgent) * float64(stamina) / float64(nLevel)) * delta * 10 * (float64(currentLevel)/float64(nLevel) + (1 / 10))))
 }
 
 // Y, monster’s damage rating
-func CalculateMonsterDamage(nAgent uint, HP uint, stamina uint, thresholdPercentage float32, nLevel uint, currentLevel uint) uint {
-	delta := CalculateDelta()
+func CalculateMonsterDamage(rng *rand.Rand, nAgent uint, HP uint, stamina uint, thresholdPercentage float32, nLevel uint, currentLevel uint) uint {
+	delta := CalculateDelta(rng)
 	// Agent Survival Threshold
 	M := math.Ceil(float64(nAgent) * float64(thresholdPercentage))
 	NFp := float64(nAgent)
@@ -32,56 +30,56 @@ func CalculateMonsterDamage(nAgent uint, HP uint, stamina uint, thresholdPercent
 	return uint(delta * (NFp / LFp) * (float64(HP) + float64(stamina)) * (2.0*float64(currentLevel)/LFp + 0.5) * (1.0 - M/NFp))
 }
 
-func GetNextLevelMonsterValues(gameConfig config.GameConfig, currentLevel uint) (uint, uint) {
-	return CalculateMonsterHealth(gameConfig.InitialNumAgents, gameConfig.Stamina, gameConfig.NumLevels, currentLevel+1), CalculateMonsterDamage(gameConfig.InitialNumAgents, gameConfig.StartingHealthPoints, gameConfig.Stamina, gameConfig.ThresholdPercentage, gameConfig.NumLevels, currentLevel+1)
+func GetNextLevelMonsterValues(rng *rand.Rand, gameConfig config.GameConfig, currentLevel uint) (uint, uint) {
+	return CalculateMonsterHealth(rng, gameConfig.InitialNumAgents, gameConfig.Stamina, gameConfig.NumLevels, currentLevel+1), CalculateMonsterDamage(rng, gameConfig.InitialNumAgents, gameConfig.StartingHealthPoints, gameConfig.Stamina, gameConfig.ThresholdPercentage, gameConfig.NumLevels, currentLevel+1)
 }
 
-func NumberPotionDropped(P float64, nAgent uint) uint {
-	delta := CalculateDelta()
+func NumberPotionDropped(rng *rand.Rand, P float64, nAgent uint) uint {
+	delta := CalculateDelta(rng)
 	return uint(delta * P * float64(nAgent))
 }
 
-func NumberEquipmentDropped(E float64, nAgent uint) uint {
-	delta := CalculateDelta()
+func NumberEquipmentDropped(rng *rand.Rand, E float64, nAgent uint) uint {
+	delta := CalculateDelta(rng)
 	return uint(delta * E * float64(nAgent))
 }
 
 // function encapsulated to use same random val
-func GetPotionDistribution(nAgent uint) (uint, uint) {
-	tau := rand.Float64()
+func GetPotionDistribution(rng *rand.Rand, nAgent uint) (uint, uint) {
+	tau := rng.Float64()
 	// hardcoded by design
 	P := 0.2
-	NumberHealthPotionDropped := uint((tau) * float64(NumberPotionDropped(P, nAgent)))
-	NumberStaminaPotionDropped := uint((1 - tau) * float64(NumberPotionDropped(P, nAgent)))
+	NumberHealthPotionDropped := uint((tau) * float64(NumberPotionDropped(rng, P, nAgent)))
+	NumberStaminaPotionDropped := uint((1 - tau) * float64(NumberPotionDropped(rng, P, nAgent)))
 	return NumberHealthPotionDropped, NumberStaminaPotionDropped
 }
 
 // tau recalculated for equipment  and potions
-func GetEquipmentDistribution(nAgent uint) (uint, uint) {
-	tau := rand.Float64()
+func GetEquipmentDistribution(rng *rand.Rand, nAgent uint) (uint, uint) {
+	tau := rng.Float64()
 	// hardcoded by design
 	E := 0.15
-	NumberWeaponDropped := uint((tau) * float64(NumberEquipmentDropped(E, nAgent)))
-	NumberShieldDropped := uint((1 - tau) * float64(NumberEquipmentDropped(E, nAgent)))
+	NumberWeaponDropped := uint((tau) * float64(NumberEquipmentDropped(rng, E, nAgent)))
+	NumberShieldDropped := uint((1 - tau) * float64(NumberEquipmentDropped(rng, E, nAgent)))
 	return NumberWeaponDropped, NumberShieldDropped
 }
 
-func GetWeaponDamage(X uint, nAgent uint) uint {
-	delta := CalculateDelta()
+func GetWeaponDamage(rng *rand.Rand, X uint, nAgent uint) uint {
+	delta := CalculateDelta(rng)
 	return uint(math.Ceil((delta * float64(X)) / (4.0 * float64(nAgent) * 0.8)))
 }
 
-func GetShieldProtection(Y uint, nAgent uint) uint {
-	delta := CalculateDelta()
+func GetShieldProtection(rng *rand.Rand, Y uint, nAgent uint) uint {
+	delta := CalculateDelta(rng)
 	return uint(math.Ceil((delta * float64(Y) * 0.5) / (float64(nAgent) * 0.8)))
 }
 
-func GetHealthPotionValue(Y uint, nAgent uint) uint {
-	delta := CalculateDelta()
+func GetHealthPotionValue(rng *rand.Rand, Y uint, nAgent uint) uint {
+	delta := CalculateDelta(rng)
 	return uint(math.Ceil((delta * float64(Y) * 5.0) / (float64(nAgent) * 0.8)))
 }
 
-func GetStaminaPotionValue(X uint, nAgent uint) uint {
-	delta := CalculateDelta()
+func GetStaminaPotionValue(rng *rand.Rand, X uint, nAgent uint) uint {
+	delta := CalculateDelta(rng)
 	return uint(math.Ceil((delta * float64(X)) / (float64(nAgent) * 0.8)))
 }
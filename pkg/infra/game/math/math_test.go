@@ -1,6 +1,7 @@
 package math_test
 
 import (
+	"math/rand"
 	"testing"
 
 	"infra/game/math"
@@ -30,10 +31,11 @@ func TestCalculateMonsterHealth(t *testing.T) {
 		},
 	}
 	for _, tt := range tests {
+		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			if got := math.CalculateMonsterHealth(tt.args.N, tt.args.ST, tt.args.L, tt.args.CL); !(tt.wantmin <= got && got <= tt.wantmax) {
+			if got := math.CalculateMonsterHealth(rand.New(rand.NewSource(1)), tt.args.N, tt.args.ST, tt.args.L, tt.args.CL); !(tt.wantmin <= got && got <= tt.wantmax) {
 				t.Errorf("CalculateMonsterHealth() = %v, wanted between %v and %v", got, tt.wantmin, tt.wantmax)
 			}
 		})
@@ -66,10 +68,11 @@ func TestCalculateMonsterDamage(t *testing.T) {
 		},
 	}
 	for _, tt := range tests {
+		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			if got := math.CalculateMonsterDamage(tt.args.N, tt.args.HP, tt.args.ST, tt.args.TH, tt.args.L, tt.args.CL); !(tt.wantmin <= got && got <= tt.wantmax) {
+			if got := math.CalculateMonsterDamage(rand.New(rand.NewSource(1)), tt.args.N, tt.args.HP, tt.args.ST, tt.args.TH, tt.args.L, tt.args.CL); !(tt.wantmin <= got && got <= tt.wantmax) {
 				t.Errorf("CalculateMonsterDamage() = %v,  wanted between %v and %v", got, tt.wantmin, tt.wantmax)
 			}
 		})
@@ -0,0 +1,44 @@
+package message
+
+import (
+	"infra/game/commons"
+	"infra/game/decision"
+)
+
+// StartConfidencePoll is sent by the server to open the discussion held before a confidence vote on Leader.
+type StartConfidencePoll struct {
+	Leader commons.ID
+}
+
+func (s StartConfidencePoll) String() string {
+	return "StartConfidencePoll"
+}
+
+func (s StartConfidencePoll) sealedMessage() {
+}
+
+func (s StartConfidencePoll) sealedInform() {
+}
+
+// ConfidenceStance tells other agents how the sender means to vote in a confidence poll, to sway them.
+// Nothing holds the sender to it.
+type ConfidenceStance struct {
+	Intent decision.Intent
+}
+
+func (s ConfidenceStance) String() string {
+	switch s.Intent {
+	case decision.Positive:
+		return "ConfidenceStance[for]"
+	case decision.Negative:
+		return "ConfidenceStance[against]"
+	default:
+		return "ConfidenceStance[abstain]"
+	}
+}
+
+func (s ConfidenceStance) sealedMessage() {
+}
+
+func (s ConfidenceStance) sealedInform() {
+}
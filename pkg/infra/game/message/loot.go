@@ -0,0 +1,89 @@
+package message
+
+import (
+	"strings"
+
+	"infra/game/state"
+)
+
+// Need is something an agent is short of, which it can make known during the loot discussion.
+type Need uint
+
+const (
+	NeedHealth Need = iota
+	NeedStamina
+	NeedWeapon
+	NeedShield
+)
+
+func (n Need) String() string {
+	switch n {
+	case NeedHealth:
+		return "health"
+	case NeedStamina:
+		return "stamina"
+	case NeedWeapon:
+		return "weapon"
+	case NeedShield:
+		return "shield"
+	default:
+		return "unknown"
+	}
+}
+
+// LootNeeds tells other agents what the sender is short of, so that the loot can go where it is most needed.
+type LootNeeds struct {
+	Needs []Need
+}
+
+// NeedsOf works out what an agent is short of: health or stamina below the low ranges agents see in
+// the view, or no weapon or shield at all.
+func NeedsOf(agentState state.AgentState) LootNeeds {
+	needs := make([]Need, 0)
+	if agentState.Hp < state.LowHealth {
+		needs = append(needs, NeedHealth)
+	}
+	if agentState.Stamina < state.LowStamina {
+		needs = append(needs, NeedStamina)
+	}
+	if agentState.Weapons.Len() == 0 {
+		needs = append(needs, NeedWeapon)
+	}
+	if agentState.Shields.Len() == 0 {
+		needs = append(needs, NeedShield)
+	}
+	return LootNeeds{Needs: needs}
+}
+
+func (l LootNeeds) String() string {
+	needs := make([]string, len(l.Needs))
+	for i, need := range l.Needs {
+		needs[i] = need.String()
+	}
+	return "LootNeeds[" + strings.Join(needs, " ") + "]"
+}
+
+func (l LootNeeds) sealedMessage() {
+}
+
+func (l LootNeeds) sealedInform() {
+}
+
+func (l LootNeeds) sealedLootInform() {
+}
+
+// LootNeedsRequest asks an agent what it is short of, to be answered with LootNeeds.
+type LootNeedsRequest struct{}
+
+func (l LootNeedsRequest) String() string {
+	return "LootNeedsRequest"
+}
+
+func (l LootNeedsRequest) sealedMessage() {
+}
+
+func (l LootNeedsRequest) sealedRequest() {
+}
+
+func (l LootNeedsRequest) sealedLootRequest() {
+}
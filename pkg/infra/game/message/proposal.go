@@ -12,6 +12,8 @@ type Proposal[A decision.ProposalAction] struct {
 	proposalID commons.ProposalID
 	proposerID commons.ID
 	rules      commons.ImmutableList[proposal.Rule[A]]
+	// target is the monster a fight proposal would have the agents fight, if it names one
+	target *decision.MonsterIdx
 }
 
 func (p Proposal[A]) ProposerID() commons.ID {
@@ -26,13 +28,34 @@ func (p Proposal[A]) Rules() commons.ImmutableList[proposal.Rule[A]] {
 	return p.rules
 }
 
+// Target returns the monster the proposal would have the agents that attack or defend fight, if it names one.
+func (p Proposal[A]) Target() (decision.MonsterIdx, bool) {
+	if p.target == nil {
+		return 0, false
+	}
+	return *p.target, true
+}
+
 func (p Proposal[A]) sealedMessage() {
 }
 
-func NewProposal[A decision.ProposalAction](rules commons.ImmutableList[proposal.Rule[A]], proposerID commons.ID) *Proposal[A] {
-	return &Proposal[A]{proposalID: uuid.NewString(), rules: rules, proposerID: proposerID}
+// NewProposal is a proposal of rules by proposerID. Its proposalID should be drawn from the proposer's seeded
+// source, see commons.NewSeededID, as ties between proposals are broken on it.
+func NewProposal[A decision.ProposalAction](proposalID commons.ProposalID, rules commons.ImmutableList[proposal.Rule[A]], proposerID commons.ID) *Proposal[A] {
+	return &Proposal[A]{proposalID: proposalID, rules: rules, proposerID: proposerID}
+}
+
+// NewTargetedProposal is a fight proposal that also names the monster to fight.
+func NewTargetedProposal(proposalID commons.ProposalID, rules commons.ImmutableList[proposal.Rule[decision.FightAction]], target decision.MonsterIdx, proposerID commons.ID) *Proposal[decision.FightAction] {
+	return &Proposal[decision.FightAction]{proposalID: proposalID, rules: rules, proposerID: proposerID, target: &target}
 }
 
 func NewProposalInternal[A decision.ProposalAction](proposalID commons.ProposalID, rules commons.ImmutableList[proposal.Rule[A]]) *Proposal[A] {
 	return &Proposal[A]{proposalID: proposalID, rules: rules, proposerID: uuid.Nil.String()}
 }
+
+// WithTarget returns a copy of the proposal naming the monster to fight, or naming none if target is nil.
+func (p Proposal[A]) WithTarget(target *decision.MonsterIdx) *Proposal[A] {
+	p.target = target
+	return &p
+}
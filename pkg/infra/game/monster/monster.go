@@ -0,0 +1,144 @@
+package monster
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+)
+
+// Ability is a monster's disruptive ability, applied on top of its attack every fight round.
+type Ability string
+
+const (
+	// None leaves the fight as it is.
+	None Ability = "none"
+	// StaminaDrain drains Strength% of the stamina of every agent that attacked or defended.
+	StaminaDrain Ability = "stamina_drain"
+	// TargetStrongest aims Strength% of the damage at the fighting agent with the highest total attack.
+	TargetStrongest Ability = "target_strongest"
+	// PierceShields ignores Strength% of the agents' combined shield.
+	PierceShields Ability = "pierce_shields"
+	// Silence stops all communication during a round's fight discussion, with a Strength% chance each round.
+	Silence Ability = "silence"
+	// Disarm destroys the weapon, or failing that the shield, in use by one fighting agent, with a Strength% chance each round.
+	Disarm Ability = "disarm"
+)
+
+func (a Ability) valid() bool {
+	switch a {
+	case None, StaminaDrain, TargetStrongest, PierceShields, Silence, Disarm:
+		return true
+	}
+	return false
+}
+
+// Definition describes one kind of monster in a catalogue.
+type Definition struct {
+	Name    string  `json:"name"`
+	Ability Ability `json:"ability"`
+	// Weight is how likely the monster is to be drawn relative to the others available at a level.
+	Weight uint `json:"weight"`
+	// MinLevel is the first level the monster can appear on.
+	MinLevel uint `json:"minLevel"`
+	// Strength is the percentage strength of the ability on level 1, growing by StrengthPerLevel every level up to 100.
+	Strength         uint `json:"strength"`
+	StrengthPerLevel uint `json:"strengthPerLevel"`
+	// Armour is the percentage of the agents' attack the monster shrugs off.
+	Armour uint `json:"armour"`
+}
+
+// Monster is a monster faced on a level, with its ability scaled to that level. Its Name is its type.
+type Monster struct {
+	Name     string
+	Ability  Ability
+	Strength uint
+	Armour   uint
+}
+
+// Catalogue is the set of monsters levels draw from.
+type Catalogue []Definition
+
+// DefaultCatalogue is used when no monster definition file is configured.
+func DefaultCatalogue() Catalogue {
+	return Catalogue{
+		{Name: "Troll", Ability: None, Weight: 4, MinLevel: 1},
+		{Name: "Leech", Ability: StaminaDrain, Weight: 2, MinLevel: 1, Strength: 5, StrengthPerLevel: 1},
+		{Name: "Hunter", Ability: TargetStrongest, Weight: 2, MinLevel: 5, Strength: 20, StrengthPerLevel: 1},
+		{Name: "Wyrm", Ability: PierceShields, Weight: 2, MinLevel: 10, Strength: 10, StrengthPerLevel: 1},
+		{Name: "Banshee", Ability: Silence, Weight: 1, MinLevel: 10, Strength: 25, StrengthPerLevel: 1},
+		{Name: "Rust Monster", Ability: Disarm, Weight: 1, MinLevel: 20, Strength: 10, StrengthPerLevel: 1},
+	}
+}
+
+// Load reads a catalogue from a JSON file holding a list of definitions.
+func Load(path string) (Catalogue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var catalogue Catalogue
+	if err := json.Unmarshal(data, &catalogue); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := catalogue.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return catalogue, nil
+}
+
+// Validate checks that every definition has a known ability and can be drawn.
+func (c Catalogue) Validate() error {
+	if len(c) == 0 {
+		return fmt.Errorf("monster catalogue is empty")
+	}
+	for i, definition := range c {
+		if !definition.Ability.valid() {
+			return fmt.Errorf("monster %d (%s) has unknown ability %q", i, definition.Name, definition.Ability)
+		}
+		if definition.Weight == 0 {
+			return fmt.Errorf("monster %d (%s) has zero weight", i, definition.Name)
+		}
+		if definition.Armour > 100 {
+			return fmt.Errorf("monster %d (%s) has armour over 100%%", i, definition.Name)
+		}
+	}
+	return nil
+}
+
+// Draw picks the monster for a level, weighted among the definitions available on it.
+// A level no definition is available on gets a monster without an ability.
+func (c Catalogue) Draw(rng *rand.Rand, level uint) Monster {
+	total := uint(0)
+	for _, definition := range c {
+		if definition.MinLevel <= level {
+			total += definition.Weight
+		}
+	}
+	if total == 0 {
+		return Monster{Name: "Monster", Ability: None}
+	}
+
+	pick := uint(rng.Int63n(int64(total)))
+	for _, definition := range c {
+		if definition.MinLevel > level {
+			continue
+		}
+		if pick < definition.Weight {
+			return definition.scale(level)
+		}
+		pick -= definition.Weight
+	}
+	panic("unreachable")
+}
+
+func (d Definition) scale(level uint) Monster {
+	strength := d.Strength
+	if level > 1 {
+		strength += d.StrengthPerLevel * (level - 1)
+	}
+	if strength > 100 {
+		strength = 100
+	}
+	return Monster{Name: d.Name, Ability: d.Ability, Strength: strength, Armour: d.Armour}
+}
@@ -0,0 +1,70 @@
+package monster_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"infra/game/monster"
+)
+
+func TestDraw(t *testing.T) {
+	t.Parallel()
+
+	catalogue := monster.Catalogue{
+		{Name: "Leech", Ability: monster.StaminaDrain, Weight: 1, MinLevel: 1, Strength: 50, StrengthPerLevel: 10},
+		{Name: "Banshee", Ability: monster.Silence, Weight: 1, MinLevel: 5, Strength: 10},
+	}
+	tests := []struct {
+		name         string
+		level        uint
+		wantStrength map[string]uint
+	}{
+		{"first level", 1, map[string]uint{"Leech": 50}},
+		{"scaled", 3, map[string]uint{"Leech": 70}},
+		{"capped", 10, map[string]uint{"Leech": 100, "Banshee": 10}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rng := rand.New(rand.NewSource(1))
+			drawn := make(map[string]bool)
+			for i := 0; i < 100; i++ {
+				m := catalogue.Draw(rng, tt.level)
+				want, ok := tt.wantStrength[m.Name]
+				if !ok || m.Strength != want {
+					t.Fatalf("Draw(level %d) = %+v; want one of %v", tt.level, m, tt.wantStrength)
+				}
+				drawn[m.Name] = true
+			}
+			if len(drawn) != len(tt.wantStrength) {
+				t.Errorf("Draw(level %d) drew %v; want all of %v", tt.level, drawn, tt.wantStrength)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		catalogue monster.Catalogue
+		wantErr   bool
+	}{
+		{"default", monster.DefaultCatalogue(), false},
+		{"empty", monster.Catalogue{}, true},
+		{"unknown ability", monster.Catalogue{{Name: "Mimic", Ability: "mimicry", Weight: 1}}, true},
+		{"zero weight", monster.Catalogue{{Name: "Troll", Ability: monster.None}}, true},
+		{"impenetrable", monster.Catalogue{{Name: "Golem", Ability: monster.None, Weight: 1, Armour: 101}}, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.catalogue.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v; want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
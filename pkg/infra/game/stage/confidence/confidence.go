@@ -1 +1,94 @@
 package confidence
+
+import (
+	"infra/config"
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/message"
+	"infra/game/stage/discussion"
+	"infra/game/state"
+	"infra/logging"
+
+	"github.com/google/uuid"
+)
+
+// Result is how a confidence vote on the leader went.
+type Result struct {
+	For     uint
+	Against uint
+	Abstain uint
+	// Threshold is the percentage of the counted votes against the leader needed to recall it: the overthrow
+	// threshold of its manifesto, which they must exceed, or the supermajority of the recall rules, which they
+	// must reach
+	Threshold uint
+	// Quorate is set when enough votes were counted for the vote to stand, and Recalled when it went against
+	// the leader
+	Quorate  bool
+	Recalled bool
+}
+
+// Due reports whether a confidence vote is held on level, which is not the case for the cooldown after a recall.
+func Due(level uint, lastRecall uint, rules config.RecallRules) bool {
+	return lastRecall == 0 || level > lastRecall+rules.Cooldown
+}
+
+// Poll runs the discussion held before a confidence vote on the leader, in which the agents may sway each other,
+// then asks every agent how it votes.
+func Poll(s state.State, agents map[commons.ID]agent.Agent, budget discussion.Budget) map[commons.ID]decision.Intent {
+	start := *message.NewTaggedMessage("server", message.StartConfidencePoll{Leader: s.CurrentLeader}, uuid.Nil)
+	rounds := make(map[commons.ID]chan<- []message.TaggedMessage, len(agents))
+	initial := make(map[commons.ID][]message.TaggedMessage, len(agents))
+	reports := make(chan agent.RoundReport, len(agents))
+	for id, a := range agents {
+		a := a
+		round := make(chan []message.TaggedMessage)
+		rounds[id] = round
+		initial[id] = []message.TaggedMessage{start}
+		go (&a).HandleConfidenceDiscussion(s.AgentState[id], round, reports)
+	}
+	stats := discussion.Run(rounds, reports, initial, budget, nil)
+	logging.Log(logging.Debug, logging.LogField{
+		"rounds":    stats.Rounds,
+		"delivered": stats.Delivered,
+		"dropped":   stats.Dropped,
+	}, "Confidence discussion finished")
+
+	intents := make(map[commons.ID]decision.Intent, len(agents))
+	for id, a := range agents {
+		intents[id] = a.HandleNoConfidenceVote(s.AgentState[id])
+	}
+	return intents
+}
+
+// Count counts the votes of a confidence vote held among living agents on a leader whose manifesto has the given
+// overthrow threshold.
+func Count(votes map[commons.ID]decision.Intent, living uint, threshold uint, rules config.RecallRules) Result {
+	result := Result{Threshold: threshold}
+	for _, intent := range votes {
+		switch intent {
+		case decision.Positive:
+			result.For++
+		case decision.Negative:
+			result.Against++
+		default:
+			result.Abstain++
+		}
+	}
+
+	counted := result.For + result.Against
+	if rules.CountAbstentions {
+		counted += result.Abstain
+	}
+	result.Quorate = counted != 0 && 100*counted >= rules.Quorum*living
+	if !result.Quorate {
+		return result
+	}
+	if rules.Supermajority != 0 {
+		result.Threshold = rules.Supermajority
+		result.Recalled = 100*result.Against >= rules.Supermajority*counted
+	} else {
+		result.Recalled = 100*result.Against/counted > threshold
+	}
+	return result
+}
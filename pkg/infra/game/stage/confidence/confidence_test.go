@@ -0,0 +1,44 @@
+package confidence_test
+
+import (
+	"testing"
+
+	"infra/config"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/stage/confidence"
+)
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+
+	// 3 for, 4 against and 3 abstaining out of 20 living agents
+	votes := make(map[commons.ID]decision.Intent)
+	for i, intent := range []decision.Intent{0, 0, 0, 1, 1, 1, 1, 2, 2, 2} {
+		votes[commons.ID(rune('a'+i))] = intent
+	}
+
+	tests := []struct {
+		name         string
+		rules        config.RecallRules
+		wantQuorate  bool
+		wantRecalled bool
+	}{
+		{"against exceeds the manifesto's threshold", config.RecallRules{}, true, true},
+		{"abstentions dilute the votes against", config.RecallRules{CountAbstentions: true}, true, false},
+		{"too few votes for the quorum", config.RecallRules{Quorum: 40}, false, false},
+		{"abstentions make up the quorum", config.RecallRules{Quorum: 50, CountAbstentions: true}, true, false},
+		{"short of the supermajority", config.RecallRules{Supermajority: 60}, true, false},
+		{"supermajority reached exactly", config.RecallRules{Supermajority: 57}, true, true},
+	}
+	for _, tt := range tests {
+		result := confidence.Count(votes, 20, 50, tt.rules)
+		if result.Quorate != tt.wantQuorate || result.Recalled != tt.wantRecalled {
+			t.Errorf("%s: Count() = %+v, expected quorate %v and recalled %v", tt.name, result, tt.wantQuorate, tt.wantRecalled)
+		}
+	}
+
+	if confidence.Due(5, 3, config.RecallRules{Cooldown: 2}) || !confidence.Due(6, 3, config.RecallRules{Cooldown: 2}) {
+		t.Error("Due() should skip the two levels after a recall on level 3")
+	}
+}
@@ -0,0 +1,118 @@
+package discussion
+
+import (
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/message"
+
+	"github.com/google/uuid"
+)
+
+// Budget bounds a discussion stage. A zero field means no limit.
+type Budget struct {
+	// Rounds is the maximum number of rounds before the stage is closed.
+	Rounds uint
+	// Messages is the maximum number of agent messages delivered over the whole stage.
+	Messages uint
+}
+
+// Stats describes how a discussion stage went.
+type Stats struct {
+	Rounds    uint
+	Delivered uint
+	// Dropped counts messages that were sent but never delivered because the budget ran out.
+	Dropped uint
+}
+
+// Entry is a message delivered during a discussion stage, as recorded in its transcript.
+type Entry struct {
+	Round  uint
+	Sender commons.ID
+	// Recipients is nil if the message was broadcast to every other participant.
+	Recipients []commons.ID
+	Message    message.Message
+
+	mID uuid.UUID
+}
+
+// Run drives a discussion stage in rounds. Each round every participant is sent its inbox, starting
+// with initial, and handles it before reporting the messages it sent in response. Those are delivered
+// in the next round, ordered by sender ID and then by the order they were sent, so the exchange is the
+// same however the agents' goroutines are scheduled. The stage ends as soon as a round produces no
+// messages or the budget is exhausted; the participants' round channels are then closed.
+// If record is not nil it is given every message delivered from the participants, in delivery order.
+func Run(participants map[commons.ID]chan<- []message.TaggedMessage,
+	reports <-chan agent.RoundReport,
+	initial map[commons.ID][]message.TaggedMessage,
+	budget Budget,
+	record func(Entry),
+) Stats {
+	stats := Stats{}
+	inboxes := initial
+	for {
+		for id, round := range participants {
+			round <- inboxes[id]
+		}
+		outboxes := make(map[commons.ID][]agent.Envelope, len(participants))
+		for range participants {
+			report := <-reports
+			outboxes[report.Agent] = report.Outbox
+		}
+		stats.Rounds++
+
+		inboxes = make(map[commons.ID][]message.TaggedMessage)
+		delivering := uint(0)
+		var entries []Entry
+		for _, sender := range commons.SortedKeys(outboxes) {
+			for _, envelope := range outboxes[sender] {
+				if _, ok := participants[envelope.Recipient]; !ok {
+					continue
+				}
+				if budget.Messages != 0 && stats.Delivered+delivering >= budget.Messages {
+					stats.Dropped++
+					continue
+				}
+				inboxes[envelope.Recipient] = append(inboxes[envelope.Recipient], envelope.Message)
+				delivering++
+				if record != nil {
+					entries = transcribe(entries, stats.Rounds+1, sender, envelope)
+				}
+			}
+		}
+
+		if delivering == 0 {
+			break
+		}
+		if budget.Rounds != 0 && stats.Rounds >= budget.Rounds {
+			stats.Dropped += delivering
+			break
+		}
+		stats.Delivered += delivering
+		for _, entry := range entries {
+			if len(entry.Recipients) == len(participants)-1 && len(participants) > 2 {
+				entry.Recipients = nil
+			}
+			record(entry)
+		}
+	}
+
+	for _, round := range participants {
+		close(round)
+	}
+	return stats
+}
+
+// transcribe adds a delivered envelope to the round's entries, merging the copies of a broadcast message.
+func transcribe(entries []Entry, round uint, sender commons.ID, envelope agent.Envelope) []Entry {
+	if last := len(entries) - 1; last >= 0 && entries[last].Sender == sender && entries[last].mID == envelope.Message.MID() {
+		entries[last].Recipients = append(entries[last].Recipients, envelope.Recipient)
+		return entries
+	}
+	return append(entries, Entry{
+		Round:      round,
+		Sender:     sender,
+		Recipients: []commons.ID{envelope.Recipient},
+		Message:    envelope.Message.Message(),
+		mID:        envelope.Message.MID(),
+	})
+}
@@ -9,13 +9,14 @@ import (
 	"infra/game/state"
 	"infra/game/tally"
 	"math/rand"
-
-	"golang.org/x/exp/maps"
+	"sort"
 
 	"github.com/benbjohnson/immutable"
 )
 
-func ResolveFightDiscussion(gs state.State, agentMap map[commons.ID]agent.Agent, currentLeader agent.Agent, manifesto decision.Manifesto, tally *tally.Tally[decision.FightAction]) decision.FightResult {
+// ResolveFightDiscussion works out the actions the agents take in a fight round, along with the breaches of the
+// manifesto's policies in the leader's resolution if it has the power to impose one.
+func ResolveFightDiscussion(gs state.State, agentMap map[commons.ID]agent.Agent, currentLeader agent.Agent, manifesto decision.Manifesto, tally *tally.Tally[decision.FightAction]) (decision.FightResult, []decision.Breach) {
 	fightActions := make(map[commons.ID]decision.FightAction)
 	prop := tally.GetMax()
 	rules := prop.Rules()
@@ -23,13 +24,13 @@ func ResolveFightDiscussion(gs state.State, agentMap map[commons.ID]agent.Agent,
 	predicate := proposal.ToSinglePredicate(rules)
 	if predicate == nil {
 		for id, a := range agentMap {
-			fightActions[id] = a.FightActionNoProposal(*a.BaseAgent)
+			fightActions[id] = a.HandleFightActionNoProposal()
 		}
 	} else {
 		for id, a := range agentMap {
 			expectedFightAction := predicate(a.AgentState())
 			if gs.Defection {
-				fightActions[id] = a.FightAction(*a.BaseAgent, expectedFightAction, prop)
+				fightActions[id] = a.HandleFightAction(expectedFightAction, prop)
 				if expectedFightAction != fightActions[id] {
 					agentState := gs.AgentState[id]
 					agentState.Defector.SetFight(true)
@@ -41,9 +42,11 @@ func ResolveFightDiscussion(gs state.State, agentMap map[commons.ID]agent.Agent,
 		}
 	}
 
+	var breaches []decision.Breach
 	if manifesto.FightDecisionPower() && currentLeader.Strategy != nil {
-		resolution := currentLeader.Strategy.FightResolution(*currentLeader.BaseAgent, rules, commons.MapToImmutable(fightActions))
+		resolution := currentLeader.HandleFightResolution(rules, commons.MapToImmutable(fightActions))
 		handleDefectionFight(gs, agentMap, resolution, fightActions, prop)
+		breaches = cowerBreaches(manifesto.Policies(), len(agentMap), resolution)
 	}
 
 	return decision.FightResult{
@@ -53,14 +56,50 @@ func ResolveFightDiscussion(gs state.State, agentMap map[commons.ID]agent.Agent,
 		CoweringAgents:  nil,
 		AttackSum:       0,
 		ShieldSum:       0,
+		Targets:         fightTargets(gs, agentMap, fightActions, prop),
+	}, breaches
+}
+
+// cowerBreaches returns a breach of the cower cap if the leader's resolution tells more agents to cower than it allows.
+func cowerBreaches(policies decision.Policies, living int, resolution immutable.Map[commons.ID, decision.FightAction]) []decision.Breach {
+	limit, capped := policies.CowerLimit(living)
+	if !capped {
+		return nil
+	}
+	cowering := make([]commons.ID, 0)
+	iterator := resolution.Iterator()
+	for !iterator.Done() {
+		id, action, _ := iterator.Next()
+		if action == decision.Cower {
+			cowering = append(cowering, id)
+		}
+	}
+	if len(cowering) <= limit {
+		return nil
+	}
+	sort.Strings(cowering)
+	return []decision.Breach{{Policy: decision.CowerCapPolicy, Agents: cowering}}
+}
+
+// fightTargets asks every agent that attacks or defends which monster it fights.
+func fightTargets(gs state.State, agentMap map[commons.ID]agent.Agent, fightActions map[commons.ID]decision.FightAction, prop message.Proposal[decision.FightAction]) map[commons.ID]decision.MonsterIdx {
+	proposed, _ := prop.Target()
+	proposed = gs.Monsters.Target(proposed)
+	targets := make(map[commons.ID]decision.MonsterIdx)
+	for _, id := range commons.SortedKeys(fightActions) {
+		a, ok := agentMap[id]
+		if action := fightActions[id]; ok && action != decision.Cower {
+			targets[id] = gs.Monsters.Target(a.HandleFightTarget(action, proposed))
+		}
 	}
+	return targets
 }
 
 func handleDefectionFight(gs state.State, agentMap map[commons.ID]agent.Agent, resolution immutable.Map[commons.ID, decision.FightAction], fightActions map[commons.ID]decision.FightAction, prop message.Proposal[decision.FightAction]) {
 	for id, a := range agentMap {
 		value, ok := resolution.Get(id)
 		if ok {
-			actualAction := a.FightAction(*a.BaseAgent, value, prop)
+			actualAction := a.HandleFightAction(value, prop)
 			if actualAction != value {
 				agentState := gs.AgentState[id]
 				agentState.Defector.SetFight(true)
@@ -68,11 +107,14 @@ func handleDefectionFight(gs state.State, agentMap map[commons.ID]agent.Agent, r
 			}
 			fightActions[id] = actualAction
 		} else {
-			fightActions[id] = a.FightActionNoProposal(*a.BaseAgent)
+			fightActions[id] = a.HandleFightActionNoProposal()
 		}
 	}
 }
 
+// ResolveLootDiscussion works out which items each agent gets, serving them in the order of the manifesto's loot
+// priority. If the leader has the power to impose an allocation, the breaches of the manifesto's policies in it are
+// returned as well, for which the agents that defected during the level are needed.
 func ResolveLootDiscussion(
 	gs state.State,
 	agentMap map[commons.ID]agent.Agent,
@@ -80,11 +122,16 @@ func ResolveLootDiscussion(
 	leader agent.Agent,
 	manifesto decision.Manifesto,
 	tally *tally.Tally[decision.LootAction],
-) immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]] {
+	defectors map[commons.ID]struct{},
+	rng *rand.Rand,
+) (immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]], []decision.Breach) {
 	prop := tally.GetMax()
-	allocation := getAllocation(gs, agentMap, pool, prop)
+	priority := manifesto.Policies().LootPriority
+	order := gs.LootOrder(priority, commons.SortedKeys(agentMap))
+	allocation := getAllocation(gs, agentMap, pool, prop, order, priority, rng)
 	if manifesto.LootDecisionPower() && leader.Strategy != nil {
-		leaderAllocation := leader.Strategy.LootAllocation(*leader.BaseAgent, prop, allocation)
+		leaderAllocation := leader.HandleLootAllocation(prop, allocation)
+		breaches := lootBreaches(manifesto.Policies(), order, allocation, leaderAllocation, defectors)
 		iterator := leaderAllocation.Iterator()
 		actualAllocation := make(map[commons.ID]immutable.SortedMap[commons.ItemID, struct{}])
 
@@ -108,19 +155,82 @@ func ResolveLootDiscussion(
 			}
 		}
 
-		return convertAllocationMapToImmutable(formAllocationFromConflicts(wantedItems))
+		return convertAllocationMapToImmutable(formAllocationFromConflicts(wantedItems, ranks(order, priority), rng)), breaches
 	} else {
-		return allocation
+		return allocation, nil
 	}
 }
 
-func getAllocation(gs state.State, agentMap map[commons.ID]agent.Agent, pool *state.LootPool, prop message.Proposal[decision.LootAction]) immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]] {
+// lootBreaches returns the breaches of the policies in the leader's allocation: the agents it passed over for
+// agents behind them in the loot priority, and the defectors it gave loot to when they should forfeit it.
+// Only the agents that wanted loot in the allocation put to the leader can be passed over.
+func lootBreaches(
+	policies decision.Policies,
+	order []commons.ID,
+	wanted immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]],
+	leaderAllocation immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]],
+	defectors map[commons.ID]struct{},
+) []decision.Breach {
+	served := func(allocation immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]], id commons.ID) bool {
+		items, ok := allocation.Get(id)
+		return ok && items.Len() > 0
+	}
+	breaches := make([]decision.Breach, 0)
+	if policies.LootPriority != decision.NoLootPriority {
+		passedOver, waiting := make([]commons.ID, 0), make([]commons.ID, 0)
+		for _, id := range order {
+			if served(leaderAllocation, id) {
+				passedOver = append(passedOver, waiting...)
+				waiting = waiting[:0]
+			} else if served(wanted, id) {
+				waiting = append(waiting, id)
+			}
+		}
+		if len(passedOver) > 0 {
+			breaches = append(breaches, decision.Breach{Policy: decision.LootPriorityPolicy, Agents: passedOver})
+		}
+	}
+	if policies.DefectorPunishment == decision.ForfeitLoot {
+		rewarded := make([]commons.ID, 0)
+		for _, id := range commons.SortedKeys(defectors) {
+			if served(leaderAllocation, id) {
+				rewarded = append(rewarded, id)
+			}
+		}
+		if len(rewarded) > 0 {
+			breaches = append(breaches, decision.Breach{Policy: decision.DefectorPunishmentPolicy, Agents: rewarded})
+		}
+	}
+	return breaches
+}
+
+// ranks returns the place of every agent in the order of the loot priority, or nil if there is no priority.
+func ranks(order []commons.ID, priority decision.LootPriority) map[commons.ID]int {
+	if priority == decision.NoLootPriority {
+		return nil
+	}
+	rank := make(map[commons.ID]int, len(order))
+	for i, id := range order {
+		rank[id] = i
+	}
+	return rank
+}
+
+func getAllocation(
+	gs state.State,
+	agentMap map[commons.ID]agent.Agent,
+	pool *state.LootPool,
+	prop message.Proposal[decision.LootAction],
+	order []commons.ID,
+	priority decision.LootPriority,
+	rng *rand.Rand,
+) immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]] {
 	predicate := proposal.ToMultiPredicate(prop.Rules())
 	if predicate == nil {
 		// either leader died or no proposal was made
-		return handleNilLootAllocation(agentMap)
+		return handleNilLootAllocation(agentMap, ranks(order, priority), rng)
 	}
-	getsWeapon, getsShield, getsHealthPotion, getsStaminaPotion := demandList(gs, agentMap, predicate)
+	getsWeapon, getsShield, getsHealthPotion, getsStaminaPotion := demandList(gs, order, predicate)
 	m := make(map[commons.ID]map[commons.ItemID]struct{})
 	buildAllocation(pool.Weapons(), getsWeapon, m)
 	buildAllocation(pool.Shields(), getsShield, m)
@@ -132,17 +242,17 @@ func getAllocation(gs state.State, agentMap map[commons.ID]agent.Agent, pool *st
 	for id, itemIDS := range m {
 		alloc := commons.MapToSortedImmutable[commons.ItemID, struct{}](itemIDS)
 		if gs.Defection {
-			agentLoot := agentMap[id].Strategy.LootAction(*agentMap[id].BaseAgent, alloc, prop)
+			a := agentMap[id]
+			agentLoot := a.HandleLootAction(alloc, prop)
 			addWantedLootToItemAllocMap(agentLoot, wantedItems, id)
 			if !commons.ImmutableSetEquality(alloc, agentLoot) {
-				defector := gs.AgentState[id].Defector
-				defector.SetLoot(true)
+				gs.MarkLootDefector(id)
 			}
 		} else {
 			addWantedLootToItemAllocMap(alloc, wantedItems, id)
 		}
 	}
-	return convertAllocationMapToImmutable(formAllocationFromConflicts(wantedItems))
+	return convertAllocationMapToImmutable(formAllocationFromConflicts(wantedItems, ranks(order, priority), rng))
 }
 
 func handleDefectionLoot(
@@ -155,25 +265,26 @@ func handleDefectionLoot(
 	for !iterator.Done() {
 		agentID, allocation, _ := iterator.Next()
 		a := agentMap[agentID]
-		newAllocation := a.LootAction(*a.BaseAgent, allocation, prop)
+		newAllocation := a.HandleLootAction(allocation, prop)
 		if !commons.ImmutableSetEquality(newAllocation, allocation) {
-			defector := gs.AgentState[agentID].Defector
-			defector.SetLoot(true)
+			gs.MarkLootDefector(agentID)
 		}
 		actualAllocation[agentID] = newAllocation
 	}
 }
 
+// demandList lists the agents that get each kind of item, visiting them in order so that items are handed out
+// in the order of the loot priority, and in the same order for a given seed.
 func demandList(
 	gs state.State,
-	agentMap map[commons.ID]agent.Agent,
+	order []commons.ID,
 	predicate func(state.AgentState) map[decision.LootAction]struct{},
 ) ([]commons.ID, []commons.ID, []commons.ID, []commons.ID) {
 	getsWeapon := make([]commons.ID, 0)
 	getsShield := make([]commons.ID, 0)
 	getsHealthPotion := make([]commons.ID, 0)
 	getsStaminaPotion := make([]commons.ID, 0)
-	for id := range agentMap {
+	for _, id := range order {
 		actions := predicate(gs.AgentState[id])
 		if _, ok := actions[decision.Weapon]; ok {
 			getsWeapon = append(getsWeapon, id)
@@ -191,13 +302,13 @@ func demandList(
 	return getsWeapon, getsShield, getsHealthPotion, getsStaminaPotion
 }
 
-func handleNilLootAllocation(agentMap map[commons.ID]agent.Agent) immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]] {
+func handleNilLootAllocation(agentMap map[commons.ID]agent.Agent, rank map[commons.ID]int, rng *rand.Rand) immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]] {
 	wantedItems := make(map[commons.ItemID]map[commons.ID]struct{})
 	for id, a := range agentMap {
-		wantedLoot := a.Strategy.LootActionNoProposal(*agentMap[id].BaseAgent)
+		wantedLoot := a.HandleLootActionNoProposal()
 		addWantedLootToItemAllocMap(wantedLoot, wantedItems, id)
 	}
-	allocations := formAllocationFromConflicts(wantedItems)
+	allocations := formAllocationFromConflicts(wantedItems, rank, rng)
 
 	return convertAllocationMapToImmutable(allocations)
 }
@@ -210,17 +321,27 @@ func convertAllocationMapToImmutable(allocations map[commons.ID]map[commons.Item
 	return commons.MapToImmutable(mMapped)
 }
 
-func formAllocationFromConflicts(wantedItems map[commons.ItemID]map[commons.ID]struct{}) map[commons.ID]map[commons.ItemID]struct{} {
+// formAllocationFromConflicts gives each wanted item to the agent that wants it with the lowest rank or, without
+// ranks, to one chosen at random. Items and agents are visited in sorted order so that the draw is reproducible
+// for a given seed.
+func formAllocationFromConflicts(wantedItems map[commons.ItemID]map[commons.ID]struct{}, rank map[commons.ID]int, rng *rand.Rand) map[commons.ID]map[commons.ItemID]struct{} {
 	allocations := make(map[commons.ID]map[commons.ItemID]struct{})
-	for item, agentSet := range wantedItems {
-		agents := maps.Keys(agentSet)
+	for _, item := range commons.SortedKeys(wantedItems) {
+		agents := commons.SortedKeys(wantedItems[item])
 		if len(agents) > 0 {
-			if m, ok := allocations[agents[rand.Intn(len(agents))]]; ok {
+			var winner commons.ID
+			if rank == nil {
+				winner = agents[rng.Intn(len(agents))]
+			} else {
+				sort.SliceStable(agents, func(i, j int) bool { return rank[agents[i]] < rank[agents[j]] })
+				winner = agents[0]
+			}
+			if m, ok := allocations[winner]; ok {
 				m[item] = struct{}{}
 			} else {
 				m := make(map[commons.ItemID]struct{})
 				m[item] = struct{}{}
-				allocations[agents[0]] = m
+				allocations[winner] = m
 			}
 		}
 	}
@@ -256,5 +377,6 @@ func buildAllocation(pool *commons.ImmutableList[state.Item], proposedLooters []
 			m[next.Id()] = struct{}{}
 			allocation[proposedLooters[idx]] = m
 		}
+		idx++
 	}
 }
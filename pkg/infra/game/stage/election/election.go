@@ -7,10 +7,12 @@ import (
 	"infra/game/commons"
 	"infra/game/decision"
 	"infra/game/state"
+	"infra/logging"
 )
 
-func HandleElection(state *state.State, agents map[commons.ID]agent.Agent, strategy decision.VotingStrategy, numberOfPreferences uint) (
-	commons.ID, decision.Manifesto,
+// CollectVotes asks every agent for a manifesto and then for its ballot, keyed by voter.
+func CollectVotes(state *state.State, agents map[commons.ID]agent.Agent, strategy decision.VotingStrategy, numberOfPreferences uint) (
+	map[commons.ID]decision.Manifesto, map[commons.ID]decision.Ballot,
 ) {
 	// Get manifestos from agents
 	agentManifestos := make(map[commons.ID]decision.Manifesto)
@@ -19,19 +21,17 @@ func HandleElection(state *state.State, agents map[commons.ID]agent.Agent, strat
 		agentManifestos[id] = *a.SubmitManifesto(state.AgentState[id])
 	}
 
-	agentIDs := make([]commons.ID, len(agents))
-
-	i := 0
-	for k := range agents {
-		agentIDs[i] = k
-		i++
-	}
-
-	ballots := make([]decision.Ballot, 0)
+	params := decision.NewElectionParams(agentManifestos, strategy, numberOfPreferences)
+	return agentManifestos, collectBallots(state, agents, params)
+}
 
-	ballotChan := make(chan decision.Ballot)
+// CollectRunoffBallots asks every agent for its ballot in the second round of a runoff between the finalists.
+func CollectRunoffBallots(state *state.State, agents map[commons.ID]agent.Agent, finalists map[commons.ID]decision.Manifesto, numberOfPreferences uint) map[commons.ID]decision.Ballot {
+	return collectBallots(state, agents, decision.NewRunoffParams(finalists, numberOfPreferences))
+}
 
-	params := decision.NewElectionParams(agentManifestos, strategy, numberOfPreferences)
+func collectBallots(state *state.State, agents map[commons.ID]agent.Agent, params *decision.ElectionParams) map[commons.ID]decision.Ballot {
+	ballotChan := make(chan agentBallot)
 
 	var wg sync.WaitGroup
 	for id, a := range agents {
@@ -44,34 +44,66 @@ func HandleElection(state *state.State, agents map[commons.ID]agent.Agent, strat
 		close(ballotChan)
 	}(&wg)
 
-	for ballot := range ballotChan {
-		ballots = append(ballots, ballot)
+	ballotMap := make(map[commons.ID]decision.Ballot)
+	for b := range ballotChan {
+		ballotMap[b.voter] = b.ballot
 	}
+	return ballotMap
+}
 
-	switch strategy {
-	case decision.VotingStrategy(decision.SingleChoicePlurality):
-		winningID := singleChoicePlurality(ballots)
-		winningManifesto := agentManifestos[winningID]
+// Finalists returns the manifestos of the candidates in the second round of the election, with the round of
+// counting that chose them, or nil if the voting rule does not call for one.
+func Finalists(manifestos map[commons.ID]decision.Manifesto, ballotMap map[commons.ID]decision.Ballot, rule VotingRule, tieBreak TieBreaker) (map[commons.ID]decision.Manifesto, Round) {
+	runoff, ok := rule.(Runoff)
+	if !ok {
+		return nil, Round{}
+	}
+	ids, round := runoff.Finalists(orderedBallots(ballotMap), commons.SortedKeys(manifestos), tieBreak)
+	if len(ids) == 0 {
+		return nil, Round{}
+	}
+	finalists := make(map[commons.ID]decision.Manifesto, len(ids))
+	for _, id := range ids {
+		finalists[id] = manifestos[id]
+	}
+	logging.Log(logging.Info, logging.LogField{"finalists": ids}, "No candidate won a majority, holding a runoff")
+	return finalists, round
+}
 
-		return winningID, winningManifesto
+// CountVotes elects one of the candidates that submitted a manifesto, according to the voting rule,
+// and returns how the votes were counted with the winner's manifesto.
+func CountVotes(manifestos map[commons.ID]decision.Manifesto, ballotMap map[commons.ID]decision.Ballot, rule VotingRule, tieBreak TieBreaker) (
+	Count, decision.Manifesto,
+) {
+	agentIDs := commons.SortedKeys(manifestos)
+	count := rule.Elect(orderedBallots(ballotMap), agentIDs, tieBreak)
 
-	case decision.VotingStrategy(decision.BordaCount):
-		winningID := BordaCount(ballots, agentIDs)
-		winningManifesto := agentManifestos[winningID]
+	// nobody cast a usable ballot, e.g. because every voter overran its budget, so settle it as a tie between all
+	if _, ok := manifestos[count.Winner]; !ok {
+		count.Winner = tieBreak(agentIDs)
+	}
 
-		return winningID, winningManifesto
-	default:
-		winningID := singleChoicePlurality(ballots)
-		winningManifesto := agentManifestos[winningID]
+	return count, manifestos[count.Winner]
+}
 
-		return winningID, winningManifesto
+// orderedBallots orders the ballots by voter so that counting does not depend on goroutine scheduling.
+func orderedBallots(ballotMap map[commons.ID]decision.Ballot) []decision.Ballot {
+	ballots := make([]decision.Ballot, 0, len(ballotMap))
+	for _, voter := range commons.SortedKeys(ballotMap) {
+		ballots = append(ballots, ballotMap[voter])
 	}
+	return ballots
+}
+
+type agentBallot struct {
+	voter  commons.ID
+	ballot decision.Ballot
 }
 
 // Create channel to a specific agent.
-func startAgentElectionHandlers(agentState state.AgentState, a agent.Agent, params *decision.ElectionParams, dChan chan<- decision.Ballot, wg *sync.WaitGroup) {
+func startAgentElectionHandlers(agentState state.AgentState, a agent.Agent, params *decision.ElectionParams, dChan chan<- agentBallot, wg *sync.WaitGroup) {
 	go func(group *sync.WaitGroup) {
-		dChan <- a.HandleElection(agentState, params)
+		dChan <- agentBallot{voter: a.ID(), ballot: a.HandleElection(agentState, params)}
 
 		group.Done()
 	}(wg)
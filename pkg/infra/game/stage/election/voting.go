@@ -4,75 +4,188 @@ import (
 	"fmt"
 	"math/rand"
 
+	"infra/config"
 	"infra/game/commons"
 	"infra/game/decision"
 	"infra/logging"
+
+	"golang.org/x/exp/constraints"
 )
 
 /*
-	Can implement multiple voting strategies in this file.
-	Problems arise when agents are given more than 2
-	choices.
-
-	C <= 2
-	1. Single/Double Choice plurality
-	C > 2
-	2. Plurality (1st rank choices)
-	3. Runoff (ask agents to vote between best 2 options)
-	4. Borda Count
-	5. Instant Runoff
-	6. Approval
-	7. Copeland Scoring
+	The voting rules, chosen by decision.VotingStrategy.
+	Ballots name candidates in order of preference, as many as the election's number of preferences.
+
+	1. Single choice plurality (1st choices only)
+	2. Borda Count
+	3. Instant Runoff
+	4. Approval
+	5. Copeland Scoring
+	6. Schulze
+	7. Two-round Runoff (ask agents to vote between the best 2 options)
 */
 
-func singleChoicePlurality(ballots []decision.Ballot) commons.ID {
-	// Count number of votes collected for each candidate
-	votes := make(map[commons.ID]uint)
+// VotingRule elects a leader from the voters' ballots, given in voter order, out of the candidates, given in ID
+// order. Ties are settled by tieBreak so that seeded games are reproducible.
+type VotingRule interface {
+	Elect(ballots []decision.Ballot, candidates []commons.ID, tieBreak TieBreaker) Count
+}
 
-	for _, ballot := range ballots {
-		if len(ballot) > 0 {
-			votes[ballot[0]]++
+// Runoff is a VotingRule that can hold a second round. Finalists returns the candidates the voters are asked to
+// choose between again, with the round of counting that chose them, or no finalists if the first round settled the
+// election, in which case Elect is given its ballots. Otherwise Elect is given the second round's ballots and the
+// finalists as candidates.
+type Runoff interface {
+	VotingRule
+	Finalists(ballots []decision.Ballot, candidates []commons.ID, tieBreak TieBreaker) ([]commons.ID, Round)
+}
+
+// Count is how a voting rule arrived at its winner, which is empty if no ballot could be counted.
+type Count struct {
+	Winner commons.ID
+	// Rounds are the rounds of counting in order. Rules that count once have a single round.
+	Rounds []Round
+}
+
+// RunnerUp returns the candidate other than the winner with the highest score in the last round of counting,
+// the lowest ID breaking ties, or an empty ID if no other candidate was counted.
+func (c Count) RunnerUp() commons.ID {
+	if len(c.Rounds) == 0 {
+		return ""
+	}
+	scores := c.Rounds[len(c.Rounds)-1].Scores
+	var runnerUp commons.ID
+	for _, id := range commons.SortedKeys(scores) {
+		if id != c.Winner && (runnerUp == "" || scores[id] > scores[runnerUp]) {
+			runnerUp = id
 		}
 	}
+	return runnerUp
+}
 
-	// Find the candidate(s) with max number of votes
-	var maxNumVotes uint
-	winners := make([]commons.ID, 0)
+// Round is a round of counting: the score the rule gave each candidate it counted, and the candidates it eliminated.
+type Round struct {
+	Scores     map[commons.ID]float64
+	Eliminated []commons.ID
+}
 
-	for agentID, numVotes := range votes {
-		if numVotes > maxNumVotes {
-			maxNumVotes = numVotes
-			winners = []commons.ID{agentID}
-		} else if numVotes == maxNumVotes {
-			winners = append(winners, agentID)
+// singleRound is the Count of a rule that counts once.
+func singleRound[S constraints.Integer | constraints.Float](winner commons.ID, scores map[commons.ID]S) Count {
+	return Count{Winner: winner, Rounds: []Round{{Scores: toFloat(scores)}}}
+}
+
+func toFloat[S constraints.Integer | constraints.Float](scores map[commons.ID]S) map[commons.ID]float64 {
+	floats := make(map[commons.ID]float64, len(scores))
+	for id, score := range scores {
+		floats[id] = float64(score)
+	}
+	return floats
+}
+
+// NewVotingRule returns the voting rule of the strategy, single choice plurality if it names none.
+func NewVotingRule(strategy decision.VotingStrategy) VotingRule {
+	switch strategy {
+	case decision.BordaCount:
+		return bordaCount{}
+	case decision.InstantRunoff:
+		return instantRunoff{}
+	case decision.Approval:
+		return approval{}
+	case decision.Copeland:
+		return copeland{}
+	case decision.Schulze:
+		return schulze{}
+	case decision.TwoRoundRunoff:
+		return twoRoundRunoff{}
+	default:
+		return plurality{}
+	}
+}
+
+// TieBreaker picks one of the tied candidates, given in ID order.
+type TieBreaker func(tied []commons.ID) commons.ID
+
+// NewTieBreaker returns the tie-break rule named in the game config. incumbent is the current leader.
+func NewTieBreaker(rule config.TieBreak, incumbent commons.ID, rng *rand.Rand) TieBreaker {
+	random := func(tied []commons.ID) commons.ID {
+		if len(tied) == 1 {
+			return tied[0]
 		}
+		return tied[rng.Intn(len(tied))]
 	}
+	switch rule {
+	case config.TieBreakID:
+		return func(tied []commons.ID) commons.ID {
+			return tied[0]
+		}
+	case config.TieBreakIncumbent:
+		return func(tied []commons.ID) commons.ID {
+			for _, id := range tied {
+				if id == incumbent {
+					return id
+				}
+			}
+			return random(tied)
+		}
+	default:
+		return random
+	}
+}
 
-	// Randomly choose one if there are more than one winner
-	var winner commons.ID
+// pickWinner settles a tie between the winners, if there is one.
+func pickWinner(winners []commons.ID, tieBreak TieBreaker) commons.ID {
+	if len(winners) == 0 {
+		return ""
+	}
 	if len(winners) > 1 {
 		logging.Log(
 			logging.Info,
 			logging.LogField{"winners": winners},
 			"Multiple candidates with a winning number of votes",
 		)
-		randIdx := rand.Intn(len(winners))
-		winner = winners[uint(randIdx)]
-	} else {
-		winner = winners[0]
 	}
+	return tieBreak(winners)
+}
+
+type plurality struct{}
 
-	pct := 100 * maxNumVotes / uint(len(ballots))
+func (plurality) Elect(ballots []decision.Ballot, _ []commons.ID, tieBreak TieBreaker) Count {
+	return singleChoicePlurality(ballots, tieBreak)
+}
+
+func singleChoicePlurality(ballots []decision.Ballot, tieBreak TieBreaker) Count {
+	// Count number of votes collected for each candidate
+	votes := make(map[commons.ID]uint)
+
+	for _, ballot := range ballots {
+		if len(ballot) > 0 {
+			votes[ballot[0]]++
+		}
+	}
+
+	winners := most(votes)
+	winner := pickWinner(winners, tieBreak)
+	if winner == "" {
+		return Count{}
+	}
+
+	pct := 100 * votes[winner] / uint(len(ballots))
 	logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s with %d of the vote", winner, pct))
 
-	return winner
+	return singleRound(winner, votes)
+}
+
+type bordaCount struct{}
+
+func (bordaCount) Elect(ballots []decision.Ballot, candidates []commons.ID, tieBreak TieBreaker) Count {
+	return BordaCount(ballots, candidates, tieBreak)
 }
 
 // BordaCount
 // 1. ignore empty ballots
 // 2. assume points shared if not shown in non-empty ballots
-// 3. randomly select one if multiple agents get the max score.
-func BordaCount(ballots []decision.Ballot, aliveAgentIDs []commons.ID) commons.ID {
+// 3. break the tie if multiple agents get the max score.
+func BordaCount(ballots []decision.Ballot, aliveAgentIDs []commons.ID, tieBreak TieBreaker) Count {
 	N := len(aliveAgentIDs)
 	updated := make(map[commons.ID]bool)
 	scores := make(map[commons.ID]float64)
@@ -117,15 +230,14 @@ func BordaCount(ballots []decision.Ballot, aliveAgentIDs []commons.ID) commons.I
 		}
 	}
 
-	winner, score := FindBordaCountWinner(scores)
+	winner, score := FindBordaCountWinner(scores, tieBreak)
 	logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s with BC %f", winner, score))
 
-	return winner
+	return singleRound(winner, scores)
 }
 
-func FindBordaCountWinner(scores map[commons.ID]float64) (commons.ID, float64) {
+func FindBordaCountWinner(scores map[commons.ID]float64, tieBreak TieBreaker) (commons.ID, float64) {
 	// Find max score
-	winner := ""
 	maxScore := 0.0
 	for _, score := range scores {
 		if score > maxScore {
@@ -135,26 +247,304 @@ func FindBordaCountWinner(scores map[commons.ID]float64) (commons.ID, float64) {
 
 	// Find the candidate(s) with max score
 	winners := make([]commons.ID, 0)
-	for agentID, score := range scores {
-		if score == maxScore {
+	for _, agentID := range commons.SortedKeys(scores) {
+		if scores[agentID] == maxScore {
 			winners = append(winners, agentID)
 		}
 	}
 
-	// Randomly choose one if there are more than one winner
-	if len(winners) > 1 {
-		logging.Log(
-			logging.Info,
-			logging.LogField{"winners": winners},
-			"Multiple candidates with a winning number of votes",
-		)
-		randIdx := rand.Intn(len(winners))
-		winner = winners[uint(randIdx)]
-	} else {
-		if len(winners) > 0 {
-			winner = winners[0]
+	return pickWinner(winners, tieBreak), maxScore
+}
+
+type instantRunoff struct{}
+
+// Elect counts each ballot for the first of its candidates still standing, and eliminates the candidate with the
+// fewest votes, or every candidate without any at once, until one has a majority of the ballots counted.
+func (instantRunoff) Elect(ballots []decision.Ballot, candidates []commons.ID, tieBreak TieBreaker) Count {
+	standing := make(map[commons.ID]bool, len(candidates))
+	for _, id := range candidates {
+		standing[id] = true
+	}
+	var count Count
+	for round := 1; ; round++ {
+		votes := make(map[commons.ID]uint, len(standing))
+		for id := range standing {
+			votes[id] = 0
+		}
+		counted := uint(0)
+		for _, ballot := range ballots {
+			if choice, ok := firstChoice(ballot, standing); ok {
+				votes[choice]++
+				counted++
+			}
+		}
+		if counted == 0 {
+			return count
+		}
+
+		leaders := most(votes)
+		if len(standing) == 1 || 2*votes[leaders[0]] > counted {
+			count.Winner = pickWinner(leaders, tieBreak)
+			count.Rounds = append(count.Rounds, Round{Scores: toFloat(votes)})
+			logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s with %d of %d votes in round %d of instant runoff", count.Winner, votes[count.Winner], counted, round))
+			return count
+		}
+
+		eliminated := fewest(votes)
+		if votes[eliminated[0]] != 0 {
+			eliminated = []commons.ID{tieBreak(eliminated)}
+		}
+		for _, id := range eliminated {
+			delete(standing, id)
+		}
+		count.Rounds = append(count.Rounds, Round{Scores: toFloat(votes), Eliminated: eliminated})
+	}
+}
+
+type approval struct{}
+
+// Elect counts each ballot as a vote for every candidate it names.
+func (approval) Elect(ballots []decision.Ballot, candidates []commons.ID, tieBreak TieBreaker) Count {
+	standing := set(candidates)
+	approvals := make(map[commons.ID]uint)
+	for _, ballot := range ballots {
+		approved := make(map[commons.ID]bool, len(ballot))
+		for _, id := range ballot {
+			if standing[id] && !approved[id] {
+				approved[id] = true
+				approvals[id]++
+			}
 		}
 	}
 
-	return winner, maxScore
+	winner := pickWinner(most(approvals), tieBreak)
+	if winner != "" {
+		logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s with the approval of %d of %d voters", winner, approvals[winner], len(ballots)))
+	}
+	return singleRound(winner, approvals)
+}
+
+type copeland struct{}
+
+// Elect scores each candidate with the head-to-head contests it wins less those it loses.
+func (copeland) Elect(ballots []decision.Ballot, candidates []commons.ID, tieBreak TieBreaker) Count {
+	named := namedCandidates(ballots, candidates)
+	d := pairwise(ballots, named)
+	scores := make(map[commons.ID]int, len(named))
+	for i, id := range named {
+		scores[id] = 0
+		for j := range named {
+			if d[i][j] > d[j][i] {
+				scores[id]++
+			} else if d[i][j] < d[j][i] {
+				scores[id]--
+			}
+		}
+	}
+
+	winner := pickWinner(most(scores), tieBreak)
+	if winner != "" {
+		logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s with a Copeland score of %d", winner, scores[winner]))
+	}
+	return singleRound(winner, scores)
+}
+
+type schulze struct{}
+
+// Elect finds the strength of the strongest path of head-to-head wins from each candidate to each other, and elects
+// a candidate whose paths to the others are at least as strong as theirs back. Each candidate is scored with the
+// number of others its paths are stronger than.
+func (schulze) Elect(ballots []decision.Ballot, candidates []commons.ID, tieBreak TieBreaker) Count {
+	named := namedCandidates(ballots, candidates)
+	d := pairwise(ballots, named)
+	n := len(named)
+	p := make([][]uint, n)
+	for i := range p {
+		p[i] = make([]uint, n)
+		for j := range p[i] {
+			if d[i][j] > d[j][i] {
+				p[i][j] = d[i][j]
+			}
+		}
+	}
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if i == k || j == k || i == j {
+					continue
+				}
+				through := p[i][k]
+				if p[k][j] < through {
+					through = p[k][j]
+				}
+				if through > p[i][j] {
+					p[i][j] = through
+				}
+			}
+		}
+	}
+
+	winners := make([]commons.ID, 0, 1)
+	scores := make(map[commons.ID]uint, n)
+	for i, id := range named {
+		beaten := false
+		for j := range named {
+			if p[j][i] > p[i][j] {
+				beaten = true
+			} else if p[i][j] > p[j][i] {
+				scores[id]++
+			}
+		}
+		if !beaten {
+			winners = append(winners, id)
+		}
+	}
+
+	winner := pickWinner(winners, tieBreak)
+	if winner != "" {
+		logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s by the Schulze method", winner))
+	}
+	return singleRound(winner, scores)
+}
+
+type twoRoundRunoff struct{}
+
+// Finalists are the two candidates with the most first choices, unless one of them has a majority.
+func (twoRoundRunoff) Finalists(ballots []decision.Ballot, candidates []commons.ID, tieBreak TieBreaker) ([]commons.ID, Round) {
+	votes, counted := firstChoices(ballots, candidates)
+	if len(votes) < 2 {
+		return nil, Round{}
+	}
+	first := tieBreak(most(votes))
+	if 2*votes[first] > counted {
+		return nil, Round{}
+	}
+	rest := make(map[commons.ID]uint, len(votes)-1)
+	for id, v := range votes {
+		if id != first {
+			rest[id] = v
+		}
+	}
+	second := tieBreak(most(rest))
+	delete(rest, second)
+
+	finalists := []commons.ID{first, second}
+	if second < first {
+		finalists = []commons.ID{second, first}
+	}
+	return finalists, Round{Scores: toFloat(votes), Eliminated: commons.SortedKeys(rest)}
+}
+
+// Elect elects the candidate with the most first choices.
+func (twoRoundRunoff) Elect(ballots []decision.Ballot, candidates []commons.ID, tieBreak TieBreaker) Count {
+	votes, counted := firstChoices(ballots, candidates)
+	winner := pickWinner(most(votes), tieBreak)
+	if winner != "" {
+		logging.Log(logging.Info, nil, fmt.Sprintf("New leader has been elected %s with %d of %d votes", winner, votes[winner], counted))
+	}
+	return singleRound(winner, votes)
+}
+
+// firstChoices counts each ballot for the first of the candidates it names.
+// Only candidates that are some ballot's first choice are counted.
+func firstChoices(ballots []decision.Ballot, candidates []commons.ID) (votes map[commons.ID]uint, counted uint) {
+	standing := set(candidates)
+	votes = make(map[commons.ID]uint)
+	for _, ballot := range ballots {
+		if choice, ok := firstChoice(ballot, standing); ok {
+			votes[choice]++
+			counted++
+		}
+	}
+	return votes, counted
+}
+
+func firstChoice(ballot decision.Ballot, standing map[commons.ID]bool) (commons.ID, bool) {
+	for _, id := range ballot {
+		if standing[id] {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// namedCandidates returns the candidates named on at least one ballot, in ID order. As every ballot ranks the
+// candidates it names above those it leaves out, a candidate named on none cannot win a head-to-head count.
+func namedCandidates(ballots []decision.Ballot, candidates []commons.ID) []commons.ID {
+	named := make(map[commons.ID]bool)
+	for _, ballot := range ballots {
+		for _, id := range ballot {
+			named[id] = true
+		}
+	}
+	result := make([]commons.ID, 0, len(named))
+	for _, id := range candidates {
+		if named[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// pairwise returns, for every pair of candidates, the number of ballots ranking the first above the second.
+// A ballot ranks the candidates it names above those it leaves out, which it ranks equally.
+func pairwise(ballots []decision.Ballot, candidates []commons.ID) [][]uint {
+	n := len(candidates)
+	index := make(map[commons.ID]int, n)
+	for i, id := range candidates {
+		index[id] = i
+	}
+	d := make([][]uint, n)
+	for i := range d {
+		d[i] = make([]uint, n)
+	}
+	rank := make([]int, n)
+	for _, ballot := range ballots {
+		for i := range rank {
+			rank[i] = n
+		}
+		for position, id := range ballot {
+			if i, ok := index[id]; ok && rank[i] == n {
+				rank[i] = position
+			}
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if rank[i] < rank[j] {
+					d[i][j]++
+				}
+			}
+		}
+	}
+	return d
+}
+
+func set(ids []commons.ID) map[commons.ID]bool {
+	s := make(map[commons.ID]bool, len(ids))
+	for _, id := range ids {
+		s[id] = true
+	}
+	return s
+}
+
+// most returns the candidates with the highest score, in ID order.
+func most[S constraints.Ordered](scores map[commons.ID]S) []commons.ID {
+	return extreme(scores, func(a, b S) bool { return a > b })
+}
+
+// fewest returns the candidates with the lowest score, in ID order.
+func fewest[S constraints.Ordered](scores map[commons.ID]S) []commons.ID {
+	return extreme(scores, func(a, b S) bool { return a < b })
+}
+
+func extreme[S constraints.Ordered](scores map[commons.ID]S, better func(a, b S) bool) []commons.ID {
+	winners := make([]commons.ID, 0, 1)
+	for _, id := range commons.SortedKeys(scores) {
+		if len(winners) == 0 || better(scores[id], scores[winners[0]]) {
+			winners = []commons.ID{id}
+		} else if scores[id] == scores[winners[0]] {
+			winners = append(winners, id)
+		}
+	}
+	return winners
 }
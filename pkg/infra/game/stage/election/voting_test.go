@@ -0,0 +1,94 @@
+package election_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"infra/config"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/stage/election"
+)
+
+func repeat(n int, ballot decision.Ballot) []decision.Ballot {
+	ballots := make([]decision.Ballot, n)
+	for i := range ballots {
+		ballots[i] = ballot
+	}
+	return ballots
+}
+
+func TestVotingRules(t *testing.T) {
+	t.Parallel()
+
+	candidates := []commons.ID{"a", "b", "c", "d"}
+	// a has the most first choices, but b beats every other candidate head to head
+	ballots := append(append(repeat(4, decision.Ballot{"a", "b", "c"}), repeat(3, decision.Ballot{"b", "c", "a"})...), repeat(2, decision.Ballot{"c", "b", "a"})...)
+	byID := election.NewTieBreaker(config.TieBreakID, "", nil)
+
+	tests := []struct {
+		strategy decision.VotingStrategy
+		ballots  []decision.Ballot
+		want     commons.ID
+	}{
+		{decision.SingleChoicePlurality, ballots, "a"},
+		{decision.InstantRunoff, ballots, "b"},
+		{decision.Copeland, ballots, "b"},
+		{decision.Schulze, ballots, "b"},
+		{decision.Approval, []decision.Ballot{{"a", "b"}, {"b"}, {"c", "b", "b"}, {"x", "c"}}, "b"},
+		{decision.TwoRoundRunoff, repeat(5, decision.Ballot{"c"}), "c"},
+		{decision.InstantRunoff, []decision.Ballot{{}, {"x"}}, ""},
+	}
+	for _, tt := range tests {
+		if got := election.NewVotingRule(tt.strategy).Elect(tt.ballots, candidates, byID).Winner; got != tt.want {
+			t.Errorf("strategy %d elected %q; want %q", tt.strategy, got, tt.want)
+		}
+	}
+
+	// d is eliminated without a first choice, then c, whose voters carry b to a majority
+	count := election.NewVotingRule(decision.InstantRunoff).Elect(ballots, candidates, byID)
+	if len(count.Rounds) != 3 || count.Rounds[1].Eliminated[0] != "c" || count.Rounds[2].Scores["b"] != 5 {
+		t.Errorf("instant runoff counted %+v", count.Rounds)
+	}
+}
+
+func TestTwoRoundRunoff(t *testing.T) {
+	t.Parallel()
+
+	manifestos := map[commons.ID]decision.Manifesto{"a": {}, "b": {}, "c": {}}
+	rule := election.NewVotingRule(decision.TwoRoundRunoff)
+	byID := election.NewTieBreaker(config.TieBreakID, "", nil)
+
+	ballots := map[commons.ID]decision.Ballot{"1": {"a"}, "2": {"a"}, "3": {"b"}, "4": {"c"}, "5": {"c"}}
+	finalists, round := election.Finalists(manifestos, ballots, rule, byID)
+	if _, ok := finalists["b"]; len(finalists) != 2 || ok {
+		t.Errorf("Finalists() = %v; want a and c", finalists)
+	}
+	if len(round.Eliminated) != 1 || round.Eliminated[0] != "b" || round.Scores["a"] != 2 {
+		t.Errorf("Finalists() counted %+v; want b eliminated with a on 2 votes", round)
+	}
+
+	ballots["3"] = decision.Ballot{"a"}
+	if finalists, _ := election.Finalists(manifestos, ballots, rule, byID); finalists != nil {
+		t.Errorf("Finalists() = %v; want none when a candidate has a majority", finalists)
+	}
+}
+
+func TestTieBreakers(t *testing.T) {
+	t.Parallel()
+
+	tied := []commons.ID{"a", "b", "c", "d"}
+	if got := election.NewTieBreaker(config.TieBreakID, "c", nil)(tied); got != "a" {
+		t.Errorf("id tie break picked %s; want a", got)
+	}
+	if got := election.NewTieBreaker(config.TieBreakIncumbent, "c", rand.New(rand.NewSource(1)))(tied); got != "c" {
+		t.Errorf("incumbent tie break picked %s; want c", got)
+	}
+	first := election.NewTieBreaker(config.TieBreakRandom, "", rand.New(rand.NewSource(7)))
+	second := election.NewTieBreaker(config.TieBreakRandom, "", rand.New(rand.NewSource(7)))
+	for i := 0; i < 10; i++ {
+		if a, b := first(tied), second(tied); a != b {
+			t.Fatalf("random tie breaks with the same seed picked %s and %s", a, b)
+		}
+	}
+}
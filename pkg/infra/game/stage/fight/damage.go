@@ -0,0 +1,179 @@
+package fight
+
+import (
+	"math/rand"
+	"sort"
+
+	"infra/config"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/state"
+)
+
+// Target is an agent the monster hits in a fight round, with the action it took.
+type Target struct {
+	ID     commons.ID
+	Action decision.FightAction
+	State  state.AgentState
+}
+
+// DamageModel shares the damage the monster deals in a fight round between the agents it hits.
+// Split returns the damage each target takes, which adds up to all of the damage; targets it leaves out are unharmed.
+// Targets are given in ID order, and any randomness must come from rng so that seeded games are reproducible.
+type DamageModel interface {
+	Split(damage uint, targets []Target, rng *rand.Rand) map[commons.ID]uint
+}
+
+// NewDamageModel returns the damage model named in the game config.
+func NewDamageModel(name config.DamageModel) DamageModel {
+	switch name {
+	case config.DamageDefenseWeighted:
+		return defenseWeighted{}
+	case config.DamageFocusFire:
+		return focusFire{}
+	case config.DamageRandomSubset:
+		return randomSubset{}
+	case config.DamageShieldersFirst:
+		return shieldersFirst{}
+	default:
+		return evenSplit{}
+	}
+}
+
+type evenSplit struct{}
+
+func (evenSplit) Split(damage uint, targets []Target, _ *rand.Rand) map[commons.ID]uint {
+	return splitEvenly(damage, targets)
+}
+
+type defenseWeighted struct{}
+
+func (defenseWeighted) Split(damage uint, targets []Target, _ *rand.Rand) map[commons.ID]uint {
+	weights := make([]float64, len(targets))
+	for i, target := range targets {
+		weights[i] = 1 / float64(target.State.TotalDefense()+1)
+	}
+	return splitWeighted(damage, targets, weights)
+}
+
+type focusFire struct{}
+
+func (focusFire) Split(damage uint, targets []Target, _ *rand.Rand) map[commons.ID]uint {
+	attackers := make([]Target, 0, len(targets))
+	for _, target := range targets {
+		if target.Action == decision.Attack {
+			attackers = append(attackers, target)
+		}
+	}
+	if len(attackers) == 0 {
+		return splitEvenly(damage, targets)
+	}
+	sort.SliceStable(attackers, func(i, j int) bool {
+		return attackers[i].State.TotalAttack() > attackers[j].State.TotalAttack()
+	})
+	return splitEvenly(damage, attackers[:(len(attackers)+3)/4])
+}
+
+type randomSubset struct{}
+
+func (randomSubset) Split(damage uint, targets []Target, rng *rand.Rand) map[commons.ID]uint {
+	if len(targets) == 0 {
+		return nil
+	}
+	hit := make([]Target, 0, (len(targets)+1)/2)
+	for _, i := range rng.Perm(len(targets))[:(len(targets)+1)/2] {
+		hit = append(hit, targets[i])
+	}
+	// keep the shares of any remainder in ID order, whatever order the targets were drawn in
+	sort.Slice(hit, func(i, j int) bool { return hit[i].ID < hit[j].ID })
+	return splitEvenly(damage, hit)
+}
+
+type shieldersFirst struct{}
+
+func (shieldersFirst) Split(damage uint, targets []Target, _ *rand.Rand) map[commons.ID]uint {
+	shielders := make([]Target, 0, len(targets))
+	others := make([]Target, 0, len(targets))
+	for _, target := range targets {
+		if target.Action == decision.Defend {
+			shielders = append(shielders, target)
+		} else {
+			others = append(others, target)
+		}
+	}
+	if len(others) == 0 {
+		return splitEvenly(damage, shielders)
+	}
+
+	split := make(map[commons.ID]uint, len(targets))
+	// shielders absorb an even share in turn, capped at their total defense, until the damage or their shields run out
+	remaining := damage
+	for len(shielders) > 0 && remaining > 0 {
+		share := splitEvenly(remaining, shielders)
+		open := shielders[:0]
+		absorbed := uint(0)
+		for _, shielder := range shielders {
+			capacity := shielder.State.TotalDefense() - split[shielder.ID]
+			take := share[shielder.ID]
+			if take >= capacity {
+				take = capacity
+			} else {
+				open = append(open, shielder)
+			}
+			split[shielder.ID] += take
+			absorbed += take
+		}
+		if absorbed == 0 {
+			break
+		}
+		remaining -= absorbed
+		shielders = open
+	}
+	for id, d := range splitEvenly(remaining, others) {
+		split[id] += d
+	}
+	return split
+}
+
+// splitEvenly splits damage evenly between targets, the first targets taking one more each for any remainder.
+func splitEvenly(damage uint, targets []Target) map[commons.ID]uint {
+	weights := make([]float64, len(targets))
+	for i := range weights {
+		weights[i] = 1
+	}
+	return splitWeighted(damage, targets, weights)
+}
+
+// splitWeighted splits damage between targets in proportion to their weights, rounding down, then hands out what
+// rounding left over one at a time, largest fraction first and earlier targets first among equal fractions.
+func splitWeighted(damage uint, targets []Target, weights []float64) map[commons.ID]uint {
+	split := make(map[commons.ID]uint, len(targets))
+	if len(targets) == 0 {
+		return split
+	}
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+
+	fractions := make([]float64, len(targets))
+	dealt := uint(0)
+	for i, target := range targets {
+		exact := float64(damage) * weights[i] / total
+		share := uint(exact)
+		split[target.ID] = share
+		fractions[i] = exact - float64(share)
+		dealt += share
+	}
+
+	order := make([]int, len(targets))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return fractions[order[i]] > fractions[order[j]] })
+	for i := 0; dealt < damage; i = (i + 1) % len(order) {
+		split[targets[order[i]].ID]++
+		dealt++
+	}
+	return split
+}
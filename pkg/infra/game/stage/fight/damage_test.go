@@ -0,0 +1,64 @@
+package fight_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"infra/config"
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/stage/fight"
+	"infra/game/state"
+)
+
+func targets() []fight.Target {
+	return []fight.Target{
+		{ID: "a", Action: decision.Attack, State: state.AgentState{Attack: 50, Defense: 10}},
+		{ID: "b", Action: decision.Attack, State: state.AgentState{Attack: 20, Defense: 10}},
+		{ID: "c", Action: decision.Defend, State: state.AgentState{Attack: 20, Defense: 30}},
+		{ID: "d", Action: decision.Defend, State: state.AgentState{Attack: 20, Defense: 40}},
+	}
+}
+
+func TestDamageModelsDealAllDamage(t *testing.T) {
+	t.Parallel()
+
+	models := []config.DamageModel{config.DamageEven, config.DamageDefenseWeighted, config.DamageFocusFire, config.DamageRandomSubset, config.DamageShieldersFirst}
+	for _, name := range models {
+		for _, damage := range []uint{0, 1, 7, 101, 1000} {
+			split := fight.NewDamageModel(name).Split(damage, targets(), rand.New(rand.NewSource(1)))
+			sum := uint(0)
+			for _, d := range split {
+				sum += d
+			}
+			if sum != damage {
+				t.Errorf("%s split %d damage as %v, which adds up to %d", name, damage, split, sum)
+			}
+		}
+	}
+}
+
+func TestDamageModels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		model config.DamageModel
+		want  map[commons.ID]uint
+	}{
+		// the remainder goes to the first agents rather than being discarded
+		{config.DamageEven, map[commons.ID]uint{"a": 26, "b": 25, "c": 25, "d": 25}},
+		{config.DamageDefenseWeighted, map[commons.ID]uint{"a": 39, "b": 38, "c": 14, "d": 10}},
+		{config.DamageFocusFire, map[commons.ID]uint{"a": 101}},
+		// c and d absorb up to their defense of 30 and 40, the rest going to the attackers
+		{config.DamageShieldersFirst, map[commons.ID]uint{"a": 16, "b": 15, "c": 30, "d": 40}},
+	}
+	for _, tt := range tests {
+		split := fight.NewDamageModel(tt.model).Split(101, targets(), rand.New(rand.NewSource(1)))
+		for _, id := range []commons.ID{"a", "b", "c", "d"} {
+			if split[id] != tt.want[id] {
+				t.Errorf("%s split 101 damage as %v; want %v", tt.model, split, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -1,147 +1,149 @@
 package fight
 
 import (
-	"math"
-	"time"
+	"sort"
 
 	"infra/game/agent"
 	"infra/game/commons"
 	"infra/game/decision"
 	"infra/game/message"
+	"infra/game/stage/discussion"
 	"infra/game/state"
 	"infra/game/tally"
+	"infra/logging"
 
 	"github.com/benbjohnson/immutable"
 	"github.com/google/uuid"
 )
 
-func DealDamage(damageToDeal uint, agentsFighting []string, agentMap map[commons.ID]agent.Agent, globalState *state.State) {
-	splitDamage := damageToDeal / uint(len(agentsFighting))
-	for _, id := range agentsFighting {
-		agentState := globalState.AgentState[id]
-		newHP := commons.SaturatingSub(agentState.Hp, splitDamage)
-		if newHP == 0 {
-			// kill agent
-			removeItems(globalState, globalState.AgentState[id])
-
-			delete(globalState.AgentState, id)
+// DealDamage takes the damage dealt to each agent, as split by a DamageModel, off its health, killing the agents
+// left without any.
+func DealDamage(damage map[commons.ID]uint, agentMap map[commons.ID]agent.Agent, globalState *state.State) {
+	for _, id := range commons.SortedKeys(damage) {
+		if globalState.Damage(id, damage[id], state.CauseFight) {
 			delete(agentMap, id)
-		} else {
-			globalState.AgentState[id] = state.AgentState{
-				Hp:          newHP,
-				Attack:      agentState.Attack,
-				Defense:     agentState.Defense,
-				Stamina:     agentState.Stamina,
-				Weapons:     agentState.Weapons,
-				Shields:     agentState.Shields,
-				WeaponInUse: agentState.WeaponInUse,
-				ShieldInUse: agentState.ShieldInUse,
-			}
 		}
 	}
 }
 
-func removeItems(globalState *state.State, agentState state.AgentState) {
-	removeItemsFromMap(globalState.InventoryMap.Weapons, agentState.Weapons)
-	removeItemsFromMap(globalState.InventoryMap.Shields, agentState.Shields)
-}
-
-func removeItemsFromMap(m map[commons.ID]uint, l immutable.List[state.Item]) {
-	iterator := l.Iterator()
-	for !iterator.Done() {
-		_, v := iterator.Next()
-		delete(m, v.Id())
-	}
-}
-
-func AgentFightDecisions(state state.State, agents map[commons.ID]agent.Agent, previousDecisions immutable.Map[commons.ID, decision.FightAction], channelsMap map[commons.ID]chan message.TaggedMessage) *tally.Tally[decision.FightAction] {
+func AgentFightDecisions(state state.State, agents map[commons.ID]agent.Agent, previousDecisions immutable.Map[commons.ID, decision.FightAction], budget discussion.Budget) *tally.Tally[decision.FightAction] {
 	proposalVotes := make(chan commons.ProposalID)
 	proposalSubmission := make(chan message.Proposal[decision.FightAction])
 	tallyClosure := make(chan struct{})
 
 	propTally := tally.NewTally(proposalVotes, proposalSubmission, tallyClosure)
 	go propTally.HandleMessages()
-	closures := make(map[commons.ID]chan<- struct{})
+
+	startFight := *message.NewTaggedMessage("server", &message.StartFight{}, uuid.Nil)
+	rounds := make(map[commons.ID]chan<- []message.TaggedMessage, len(agents))
+	start := make(map[commons.ID][]message.TaggedMessage, len(agents))
+	reports := make(chan agent.RoundReport, len(agents))
 	for id, a := range agents {
 		a := a
-		closure := make(chan struct{})
-		closures[id] = closure
+		round := make(chan []message.TaggedMessage)
+		rounds[id] = round
+		start[id] = []message.TaggedMessage{startFight}
 		agentState := state.AgentState[a.BaseAgent.ID()]
 		if a.BaseAgent.ID() == state.CurrentLeader {
-			go (&a).HandleFight(agentState, previousDecisions, proposalVotes, proposalSubmission, closure)
+			go (&a).HandleFight(agentState, previousDecisions, proposalVotes, proposalSubmission, round, reports)
 		} else {
-			go (&a).HandleFight(agentState, previousDecisions, proposalVotes, nil, closure)
+			go (&a).HandleFight(agentState, previousDecisions, proposalVotes, nil, round, reports)
 		}
 	}
-	mID := uuid.Nil
-
-	for _, messages := range channelsMap {
-		messages <- *message.NewTaggedMessage("server", &message.StartFight{}, mID)
-	}
-	time.Sleep(100 * time.Millisecond)
-	for id, c := range channelsMap {
-		closures[id] <- struct{}{}
-		go func(recv <-chan message.TaggedMessage) {
-			for m := range recv {
-				switch m.Message().(type) {
-				case message.Request:
-					// todo: respond with nil thing here as we're closing! Or do we need to?
-					// maybe because we're closing there's no point...
-				default:
-				}
-			}
-		}(c)
-	}
 
-	for _, c := range channelsMap {
-		close(c)
-	}
+	stats := discussion.Run(rounds, reports, start, budget, nil)
+	logging.Log(logging.Debug, logging.LogField{
+		"rounds":    stats.Rounds,
+		"delivered": stats.Delivered,
+		"dropped":   stats.Dropped,
+	}, "Fight discussion finished")
 
 	tallyClosure <- struct{}{}
 	close(tallyClosure)
 	return propTally
 }
 
-func HandleFightRound(state state.State, baseHealth uint, fightResult *decision.FightResult) *state.State {
+// HandleFightRound has every agent take the action it chose against the monster it targeted, if it has the stamina
+// for it under the game's stamina model, or cower otherwise.
+func HandleFightRound(s state.State, baseHealth uint, fightResult *decision.FightResult) *state.State {
 	var attackSum uint
 	var shieldSum uint
-
-	for agentID, d := range fightResult.Choices {
-		agentState := state.AgentState[agentID]
-
-		const scalingFactor = 0.01
+	model := s.StaminaModel
+	targets := make(map[commons.ID]decision.MonsterIdx, len(fightResult.Targets))
+	attackSums := make([]uint, len(s.Monsters))
+	shieldSums := make([]uint, len(s.Monsters))
+
+	// visit agents in ID order so that the attacking/shielding/cowering lists are reproducible
+	for _, agentID := range commons.SortedKeys(fightResult.Choices) {
+		d := fightResult.Choices[agentID]
+		agentState := s.AgentState[agentID]
+		cost := state.StaminaCost(model, agentState, d)
+		target := s.Monsters.Target(fightResult.Targets[agentID])
+
+		cower := func() {
+			fightResult.CoweringAgents = append(fightResult.CoweringAgents, agentID)
+			s.Heal(agentID, (model.CowerHpPct*baseHealth+99)/100, state.CauseCower)
+			s.GainStamina(agentID, model.CowerStamina, state.CauseCower)
+		}
 		switch d {
 		case decision.Attack:
-			if agentState.Stamina > agentState.BonusAttack() {
+			if agentState.Stamina > cost {
 				fightResult.AttackingAgents = append(fightResult.AttackingAgents, agentID)
 				attackSum += agentState.TotalAttack()
-				agentState.Stamina = commons.SaturatingSub(agentState.Stamina, agentState.BonusAttack())
+				attackSums[target] += agentState.TotalAttack()
+				targets[agentID] = target
+				s.SpendStamina(agentID, cost, state.CauseAttack)
 			} else {
-				fightResult.CoweringAgents = append(fightResult.CoweringAgents, agentID)
 				fightResult.Choices[agentID] = decision.Cower
-				agentState.Hp += uint(math.Ceil(scalingFactor * float64(baseHealth)))
-				agentState.Stamina += 1
+				cower()
 			}
 		case decision.Defend:
-			if agentState.Stamina > agentState.BonusDefense() {
+			if agentState.Stamina > cost {
 				fightResult.ShieldingAgents = append(fightResult.ShieldingAgents, agentID)
 				shieldSum += agentState.TotalDefense()
-				agentState.Stamina = commons.SaturatingSub(agentState.Stamina, agentState.BonusDefense())
+				shieldSums[target] += agentState.TotalDefense()
+				targets[agentID] = target
+				s.SpendStamina(agentID, cost, state.CauseDefend)
 			} else {
-				fightResult.CoweringAgents = append(fightResult.CoweringAgents, agentID)
 				fightResult.Choices[agentID] = decision.Cower
-				agentState.Hp += uint(math.Ceil(scalingFactor * float64(baseHealth)))
-				agentState.Stamina += 1
+				cower()
 			}
 		case decision.Cower:
-			fightResult.CoweringAgents = append(fightResult.CoweringAgents, agentID)
-			agentState.Hp += uint(math.Ceil(scalingFactor * float64(baseHealth)))
-			agentState.Stamina += 1
+			cower()
 		}
-		state.AgentState[agentID] = agentState
 	}
 
 	fightResult.AttackSum = attackSum
 	fightResult.ShieldSum = shieldSum
-	return &state
+	fightResult.Targets = targets
+	fightResult.AttackSums = attackSums
+	fightResult.ShieldSums = shieldSums
+	return &s
+}
+
+// CapCowering makes the healthiest of the cowering agents defend instead, until no more of them cower than the
+// policies allow. It returns the agents made to defend, in ID order.
+func CapCowering(s state.State, choices map[commons.ID]decision.FightAction, policies decision.Policies) []commons.ID {
+	limit, capped := policies.CowerLimit(len(choices))
+	if !capped {
+		return nil
+	}
+	cowering := make([]commons.ID, 0)
+	for _, id := range commons.SortedKeys(choices) {
+		if choices[id] == decision.Cower {
+			cowering = append(cowering, id)
+		}
+	}
+	if len(cowering) <= limit {
+		return nil
+	}
+	sort.SliceStable(cowering, func(i, j int) bool {
+		return s.AgentState[cowering[i]].Hp > s.AgentState[cowering[j]].Hp
+	})
+	defending := cowering[:len(cowering)-limit]
+	for _, id := range defending {
+		choices[id] = decision.Defend
+	}
+	sort.Strings(defending)
+	return defending
 }
@@ -10,7 +10,8 @@ import (
 	"infra/logging"
 )
 
-func UpdateHpPool(agentMap map[commons.ID]agent.Agent, globalState *state.State) {
+// Donations asks every agent how much of its health it gives to the pool.
+func Donations(agentMap map[commons.ID]agent.Agent, globalState *state.State) map[commons.ID]uint {
 	var wg sync.WaitGroup
 	donationChan := make(chan decision.HpPoolDonation, len(agentMap))
 	for id, a := range agentMap {
@@ -30,12 +31,24 @@ func UpdateHpPool(agentMap map[commons.ID]agent.Agent, globalState *state.State)
 		close(donationChan)
 	}(&wg)
 
-	sum := uint(0)
+	donations := make(map[commons.ID]uint, len(agentMap))
 	for agentDonation := range donationChan {
+		donations[agentDonation.AgentID] = agentDonation.Donation
+	}
+	return donations
+}
+
+// Donate moves the donations into the pool. An agent that donates all of its health dies.
+func Donate(agentMap map[commons.ID]agent.Agent, globalState *state.State, donations map[commons.ID]uint) map[commons.ID]uint {
+	donated := make(map[commons.ID]uint, len(donations))
+	sum := uint(0)
+	for _, id := range commons.SortedKeys(donations) {
+		agentDonation := decision.HpPoolDonation{AgentID: id, Donation: donations[id]}
 		agentHp := globalState.AgentState[agentDonation.AgentID].Hp
-		if agentDonation.Donation >= agentHp {
+		if agentDonation.Donation > agentHp {
 			agentDonation.Donation = agentHp
-			delete(globalState.AgentState, agentDonation.AgentID)
+		}
+		if globalState.Damage(agentDonation.AgentID, agentDonation.Donation, state.CauseDonation) {
 			delete(agentMap, agentDonation.AgentID)
 		}
 
@@ -45,11 +58,8 @@ func UpdateHpPool(agentMap map[commons.ID]agent.Agent, globalState *state.State)
 			"New Sum":        sum + agentDonation.Donation,
 		}, "HP Pool Donation")
 
+		donated[agentDonation.AgentID] = agentDonation.Donation
 		sum += agentDonation.Donation
-		if a, ok := globalState.AgentState[agentDonation.AgentID]; ok {
-			a.Hp = agentHp - agentDonation.Donation
-			globalState.AgentState[agentDonation.AgentID] = a
-		}
 	}
 
 	logging.Log(logging.Info, logging.LogField{
@@ -59,4 +69,28 @@ func UpdateHpPool(agentMap map[commons.ID]agent.Agent, globalState *state.State)
 	}, "HP Pool Donation")
 
 	globalState.HpPool += sum
+	return donated
+}
+
+// Mandate raises every donation to the given percentage of the agent's health, short of all of it.
+// It returns the donations along with the agents whose donations were raised.
+func Mandate(globalState *state.State, donations map[commons.ID]uint, percent uint) (map[commons.ID]uint, []commons.ID) {
+	mandated := make(map[commons.ID]uint, len(donations))
+	raised := make([]commons.ID, 0)
+	for _, id := range commons.SortedKeys(donations) {
+		mandated[id] = donations[id]
+		hp := globalState.AgentState[id].Hp
+		if hp == 0 {
+			continue
+		}
+		minimum := decision.Share(hp, percent)
+		if minimum > hp-1 {
+			minimum = hp - 1
+		}
+		if donations[id] < minimum {
+			mandated[id] = minimum
+			raised = append(raised, id)
+		}
+	}
+	return mandated, raised
 }
@@ -5,6 +5,8 @@ import (
 	"infra/game/agent"
 	"infra/game/commons"
 	"infra/game/state"
+	"math/rand"
+	"time"
 
 	"github.com/benbjohnson/immutable"
 	"github.com/google/uuid"
@@ -17,12 +19,18 @@ func InstantiateAgent[S agent.Strategy](gameConfig config.GameConfig,
 	strategyConstructor func() S,
 	agentName string,
 	viewPtr *state.View,
+	rng *rand.Rand,
 ) {
 	for i := uint(0); i < quantity; i++ {
-		agentID := uuid.NewString()
+		agentID := commons.NewSeededID(rng)
+		baseAgent := agent.NewBaseAgent(nil, agentID, agentName, viewPtr, commons.NewSource(rng.Int63()))
+		strategy := strategyConstructor()
+		if initialisable, ok := any(strategy).(agent.Initialisable); ok {
+			initialisable.Init(*baseAgent)
+		}
 		agentMap[agentID] = agent.Agent{
-			BaseAgent: agent.NewBaseAgent(nil, agentID, agentName, viewPtr),
-			Strategy:  strategyConstructor(),
+			BaseAgent: baseAgent,
+			Strategy:  strategy,
 		}
 
 		agentStateMap[agentID] = state.AgentState{
@@ -50,6 +58,37 @@ func InitGameConfig() config.GameConfig {
 		VotingStrategy:         config.EnvToUint("VOTING_STRATEGY", 1),
 		VotingPreferences:      config.EnvToUint("VOTING_PREFERENCES", 2),
 		Defection:              config.EnvToBool("DEFECTION", false),
+		Seed:                   config.EnvToInt64("SEED", time.Now().UnixNano()),
+		TieBreak:               config.TieBreak(config.EnvToString("ELECTION_TIE_BREAK", string(config.TieBreakRandom))),
+		AnonymousBallots:       config.EnvToBool("ANONYMOUS_BALLOTS", false),
+		MaxDiscussionRounds:    config.EnvToUint("MAX_DISCUSSION_ROUNDS", 10),
+		MaxDiscussionMessages:  config.EnvToUint("MAX_DISCUSSION_MESSAGES", 10000),
+		MaxFightRounds:         config.EnvToUint("MAX_FIGHT_ROUNDS", 100),
+		StalemateRule:          config.StalemateRule(config.EnvToString("STALEMATE_RULE", string(config.StalemateLose))),
+		EnragePercentage:       config.EnvToUint("ENRAGE_PCT", 10),
+		DamageModel:            config.DamageModel(config.EnvToString("DAMAGE_MODEL", string(config.DamageEven))),
+		Recall: config.RecallRules{
+			Quorum:           config.EnvToUint("RECALL_QUORUM_PCT", 0),
+			Supermajority:    config.EnvToUint("RECALL_SUPERMAJORITY_PCT", 0),
+			CountAbstentions: config.EnvToBool("RECALL_COUNT_ABSTENTIONS", false),
+			Cooldown:         config.EnvToUint("RECALL_COOLDOWN_LEVELS", 0),
+			Outcome:          config.RecallOutcome(config.EnvToString("RECALL_OUTCOME", string(config.RecallElection))),
+		},
+		Succession: config.Succession(config.EnvToString("LEADER_SUCCESSION", string(config.SuccessionRunnerUp))),
+		StaminaModel: config.StaminaModel{
+			AttackCost:   config.EnvToUint("STAMINA_ATTACK_COST", 0),
+			DefendCost:   config.EnvToUint("STAMINA_DEFEND_COST", 0),
+			ItemWeight:   config.EnvToFloat("STAMINA_ITEM_WEIGHT", 1),
+			CowerStamina: config.EnvToUint("COWER_STAMINA", 1),
+			CowerHpPct:   config.EnvToUint("COWER_HP_PCT", 1),
+			RoundRegen:   config.EnvToUint("STAMINA_ROUND_REGEN", 0),
+			LevelRegen:   config.EnvToUint("STAMINA_LEVEL_REGEN", 0),
+		},
+		MonstersPerLevel:   config.EnvToUint("MONSTERS_PER_LEVEL", 1),
+		FightHistoryLevels: config.EnvToUint("FIGHT_HISTORY_LEVELS", 5),
+		TradeRounds:        config.EnvToUint("TRADE_ROUNDS", 5),
+		TradeRoundLimit:    config.EnvToUint("TRADE_ROUND_LIMIT", 3),
+		DecisionTimeout:    time.Duration(config.EnvToUint("DECISION_TIMEOUT_MS", 1000)) * time.Millisecond,
 	}
 
 	return gameConfig
@@ -59,6 +98,7 @@ func InitAgents(
 	defaultStrategyMap map[commons.ID]func() agent.Strategy,
 	gameConfig config.GameConfig,
 	ptr *state.View,
+	rng *rand.Rand,
 ) (numAgents uint, agentMap map[commons.ID]agent.Agent, agentStateMap map[commons.ID]state.AgentState, inventoryMap state.InventoryMap) {
 	agentMap = make(map[commons.ID]agent.Agent)
 	agentStateMap = make(map[commons.ID]state.AgentState)
@@ -69,12 +109,12 @@ func InitAgents(
 
 	numAgents = 0
 
-	for agentName, strategy := range defaultStrategyMap {
-		expectedEnvName := "AGENT_" + agentName + "_QUANTITY"
-		quantity := config.EnvToUint(expectedEnvName, 100)
+	// iterate in name order so that each agent draws the same ID and random source for a given seed
+	for _, agentName := range commons.SortedKeys(defaultStrategyMap) {
+		quantity := gameConfig.AgentQuantity(agentName)
 
 		numAgents += quantity
-		InstantiateAgent(gameConfig, agentMap, agentStateMap, quantity, strategy, agentName, ptr)
+		InstantiateAgent(gameConfig, agentMap, agentStateMap, quantity, defaultStrategyMap[agentName], agentName, ptr, rng)
 	}
 
 	return
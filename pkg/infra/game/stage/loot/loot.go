@@ -1,115 +1,165 @@
 package loot
 
 import (
+	"fmt"
+	"strings"
+
 	"infra/game/decision"
 	"infra/game/message"
+	"infra/game/message/proposal"
+	"infra/game/stage/discussion"
 	"infra/game/tally"
 	"infra/logging"
 	"sync"
-	"time"
 
 	"github.com/benbjohnson/immutable"
+	"github.com/google/uuid"
 
 	"infra/game/agent"
 	"infra/game/commons"
 	"infra/game/state"
 )
 
-type agentStateUpdate struct {
+// ItemChoice is the weapon and shield an agent chose to use, as indices into its inventory.
+type ItemChoice struct {
+	Weapon decision.ItemIdx
+	Shield decision.ItemIdx
+}
+
+type agentItemChoice struct {
 	commons.ID
-	state.AgentState
+	ItemChoice
 }
 
-func UpdateItems(s state.State, agents map[commons.ID]agent.Agent) *state.State {
-	updatedState := s
+// ItemChoices asks every agent which of its weapons and shields it wants to use.
+func ItemChoices(s state.State, agents map[commons.ID]agent.Agent) map[commons.ID]ItemChoice {
 	var wg sync.WaitGroup
-	updatedStates := make(chan agentStateUpdate)
+	choices := make(chan agentItemChoice)
 	for id, a := range agents {
 		wg.Add(1)
 		id := id
 		a := a
 		agentState := s.AgentState[id]
-		go func(id commons.ID, a agent.Agent, sender chan<- agentStateUpdate, wait *sync.WaitGroup) {
-			weaponId := a.HandleUpdateWeapon(agentState)
-			shieldId := a.HandleUpdateShield(agentState)
-			agentState.ChangeWeaponInUse(weaponId)
-			agentState.ChangeShieldInUse(shieldId)
-			sender <- agentStateUpdate{
+		go func(id commons.ID, a agent.Agent, sender chan<- agentItemChoice, wait *sync.WaitGroup) {
+			sender <- agentItemChoice{
 				ID:         id,
-				AgentState: agentState,
+				ItemChoice: ItemChoice{Weapon: a.HandleUpdateWeapon(agentState), Shield: a.HandleUpdateShield(agentState)},
 			}
 			wait.Done()
-		}(id, a, updatedStates, &wg)
+		}(id, a, choices, &wg)
 	}
 	go func(group *sync.WaitGroup) {
 		group.Wait()
-		close(updatedStates)
+		close(choices)
 	}(&wg)
 
-	for update := range updatedStates {
-		updatedState.AgentState[update.ID] = update.AgentState
+	itemChoices := make(map[commons.ID]ItemChoice, len(agents))
+	for choice := range choices {
+		itemChoices[choice.ID] = choice.ItemChoice
 	}
+	return itemChoices
+}
 
+// ApplyItemChoices changes the weapon and shield in use of every agent that made a choice.
+func ApplyItemChoices(s state.State, choices map[commons.ID]ItemChoice) *state.State {
+	updatedState := s
+	for id, choice := range choices {
+		agentState := s.AgentState[id]
+		agentState.ChangeWeaponInUse(choice.Weapon)
+		agentState.ChangeShieldInUse(choice.Shield)
+		updatedState.AgentState[id] = agentState
+	}
 	return &updatedState
 }
 
+// AgentLootDecisions runs the loot discussion: every agent is told about the loot pool with a StartLoot
+// message, after which they can exchange needs and put proposals to the leader to vote on.
+// The returned stage log holds the transcript of the discussion.
 func AgentLootDecisions(
 	state state.State,
 	availableLoot state.LootPool,
 	agents map[commons.ID]agent.Agent,
-	channelsMap map[commons.ID]chan message.TaggedMessage,
-) *tally.Tally[decision.LootAction] {
+	budget discussion.Budget,
+) (*tally.Tally[decision.LootAction], logging.LootStage) {
 	proposalVotes := make(chan commons.ProposalID)
 	proposalSubmission := make(chan message.Proposal[decision.LootAction])
 	tallyClosure := make(chan struct{})
 
 	propTally := tally.NewTally(proposalVotes, proposalSubmission, tallyClosure)
 	go propTally.HandleMessages()
-	closures := make(map[commons.ID]chan<- struct{})
-	starts := make(map[commons.ID]chan<- message.StartLoot)
+
+	startLoot := *message.NewTaggedMessage("server", message.NewStartLoot(availableLoot), uuid.Nil)
+	rounds := make(map[commons.ID]chan<- []message.TaggedMessage, len(agents))
+	start := make(map[commons.ID][]message.TaggedMessage, len(agents))
+	reports := make(chan agent.RoundReport, len(agents))
 	for id, a := range agents {
 		a := a
-		closure := make(chan struct{})
-		closures[id] = closure
-
-		start := make(chan message.StartLoot)
-		starts[id] = start
+		round := make(chan []message.TaggedMessage)
+		rounds[id] = round
+		start[id] = []message.TaggedMessage{startLoot}
 
 		agentState := state.AgentState[a.BaseAgent.ID()]
 		if a.BaseAgent.ID() == state.CurrentLeader {
-			go (&a).HandleLoot(agentState, proposalVotes, proposalSubmission, closure, start)
+			go (&a).HandleLoot(agentState, availableLoot, proposalVotes, proposalSubmission, round, reports)
 		} else {
-			go (&a).HandleLoot(agentState, proposalVotes, nil, closure, start)
+			go (&a).HandleLoot(agentState, availableLoot, proposalVotes, nil, round, reports)
 		}
 	}
 
-	startLootMessage := *message.NewStartLoot(availableLoot)
-	for _, start := range starts {
-		start <- startLootMessage
-	}
+	stage := logging.LootStage{Occurred: true}
+	stats := discussion.Run(rounds, reports, start, budget, func(entry discussion.Entry) {
+		stage.Transcript = append(stage.Transcript, logging.LootMessageLog{
+			Round:      entry.Round,
+			Sender:     entry.Sender,
+			Recipients: entry.Recipients,
+			Message:    describe(entry.Message),
+		})
+	})
+	stage.Rounds = stats.Rounds
+	logging.Log(logging.Debug, logging.LogField{
+		"rounds":    stats.Rounds,
+		"delivered": stats.Delivered,
+		"dropped":   stats.Dropped,
+	}, "Loot discussion finished")
 
-	time.Sleep(100 * time.Millisecond)
-	for id, c := range channelsMap {
-		closures[id] <- struct{}{}
-		go func(recv <-chan message.TaggedMessage) {
-			for m := range recv {
-				switch m.Message().(type) {
-				case message.Request:
-					// todo: respond with nil thing here as we're closing! Or do we need to?
-					// maybe because we're closing there's no point...
-				default:
-				}
-			}
-		}(c)
+	tallyClosure <- struct{}{}
+	close(tallyClosure)
+	return propTally, stage
+}
+
+// WinningProposal describes the proposal the loot allocation is based on, or returns nil if no proposal got a vote.
+func WinningProposal(propTally *tally.Tally[decision.LootAction]) *logging.LootProposalLog {
+	winner := propTally.GetMax()
+	votes := propTally.ProposalTally()[winner.ProposalID()]
+	if votes == 0 {
+		return nil
+	}
+	return &logging.LootProposalLog{
+		Proposer: propTally.Proposer(winner.ProposalID()),
+		Votes:    votes,
+		Rules:    describeRules(winner.Rules()),
 	}
+}
 
-	for _, c := range channelsMap {
-		close(c)
+func describe(m message.Message) string {
+	switch m := m.(type) {
+	case message.Proposal[decision.LootAction]:
+		return fmt.Sprintf("Proposal%v", describeRules(m.Rules()))
+	case fmt.Stringer:
+		return m.String()
+	default:
+		return strings.TrimPrefix(fmt.Sprintf("%T", m), "*message.")
 	}
+}
 
-	tallyClosure <- struct{}{}
-	close(tallyClosure)
-	return propTally
+func describeRules(rules commons.ImmutableList[proposal.Rule[decision.LootAction]]) []string {
+	actions := make([]string, 0, rules.Len())
+	iterator := rules.Iterator()
+	for !iterator.Done() {
+		rule, _ := iterator.Next()
+		actions = append(actions, rule.Action().String())
+	}
+	return actions
 }
 
 func HandleLootAllocation(globalState state.State, allocation *immutable.Map[commons.ID, immutable.SortedMap[commons.ItemID, struct{}]], pool *state.LootPool) *state.State {
@@ -125,22 +175,17 @@ func HandleLootAllocation(globalState state.State, allocation *immutable.Map[com
 		itemIterator := items.Iterator()
 		for !itemIterator.Done() {
 			item, _, _ := itemIterator.Next()
-			agentState := globalState.AgentState[agentID]
-
 			if val, ok := weaponSet[item]; ok {
-				globalState.InventoryMap.Weapons[item] = val
-				agentState.AddWeapon(*state.NewItem(item, val))
+				globalState.GiveItem(agentID, commons.Weapon, *state.NewItem(item, val))
 			} else if val, ok := shieldSet[item]; ok {
-				globalState.InventoryMap.Shields[item] = val
-				agentState.AddShield(*state.NewItem(item, val))
+				globalState.GiveItem(agentID, commons.Shield, *state.NewItem(item, val))
 			} else if val, ok := hpPotionSet[item]; ok {
-				agentState.Hp += val
+				globalState.Heal(agentID, val, state.CausePotion)
 			} else if val, ok := staminaPotionSet[item]; ok {
-				agentState.Stamina += val
+				globalState.GainStamina(agentID, val, state.CausePotion)
 			} else {
 				logging.Log(logging.Warn, nil, "unknown item attempted to be allocated")
 			}
-			globalState.AgentState[agentID] = agentState
 		}
 	}
 	return &globalState
@@ -1,12 +1,18 @@
 package internal
 
 import (
+	"fmt"
+
 	"infra/game/commons"
 	"infra/game/message"
 )
 
 type Info struct {
 	negotiations map[commons.TradeID]message.TradeNegotiation
+	// opened counts the negotiations opened so far, to number new ones
+	opened uint
+	// executed are the negotiations whose trades went through, in order
+	executed []message.TradeNegotiation
 	Inventory
 }
 
@@ -14,6 +20,22 @@ func (n *Info) Negotiations() map[commons.TradeID]message.TradeNegotiation {
 	return n.negotiations
 }
 
+// NextTradeID returns the ID for a new negotiation. IDs follow the order in which negotiations are
+// opened, so that a replayed trade stage hands out the same IDs.
+func (n *Info) NextTradeID() commons.TradeID {
+	n.opened++
+	return fmt.Sprintf("trade-%d", n.opened)
+}
+
+// Execute records that a negotiation's trade went through.
+func (n *Info) Execute(negotiation message.TradeNegotiation) {
+	n.executed = append(n.executed, negotiation)
+}
+
+func (n *Info) Executed() []message.TradeNegotiation {
+	return n.executed
+}
+
 func NewInfo(negotiations map[commons.TradeID]message.TradeNegotiation, inventory Inventory) *Info {
 	return &Info{negotiations: negotiations, Inventory: inventory}
 }
@@ -0,0 +1,82 @@
+package trade
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"infra/game/commons"
+	"infra/game/message"
+)
+
+// Move is the message an agent sent in one round of a trade stage.
+type Move struct {
+	Round   uint
+	Agent   commons.ID
+	Message message.TradeMessage
+}
+
+// moveJSON is a Move as it is written to the event log, with the kind of message spelled out.
+type moveJSON struct {
+	Round          uint
+	Agent          commons.ID
+	Kind           string
+	TradeID        commons.TradeID      `json:",omitempty"`
+	CounterPartyID commons.ID           `json:",omitempty"`
+	Offer          *message.TradeOffer  `json:",omitempty"`
+	Demand         *message.TradeDemand `json:",omitempty"`
+}
+
+func (m Move) MarshalJSON() ([]byte, error) {
+	move := moveJSON{Round: m.Round, Agent: m.Agent}
+	switch msg := m.Message.(type) {
+	case nil:
+		move.Kind = "none"
+	case message.TradeAbstain:
+		move.Kind = "abstain"
+	case message.TradeRequest:
+		move.Kind = "request"
+		move.CounterPartyID, move.Offer, move.Demand = msg.CounterPartyID, &msg.Offer, &msg.Demand
+	case message.TradeAccept:
+		move.Kind, move.TradeID = "accept", msg.TradeID
+	case message.TradeReject:
+		move.Kind, move.TradeID = "reject", msg.TradeID
+	case message.TradeBargain:
+		move.Kind, move.TradeID, move.Offer, move.Demand = "bargain", msg.TradeID, &msg.Offer, &msg.Demand
+	default:
+		return nil, fmt.Errorf("unknown trade message %T", msg)
+	}
+	return json.Marshal(move)
+}
+
+func (m *Move) UnmarshalJSON(data []byte) error {
+	var move moveJSON
+	if err := json.Unmarshal(data, &move); err != nil {
+		return err
+	}
+	var offer message.TradeOffer
+	var demand message.TradeDemand
+	if move.Offer != nil {
+		offer = *move.Offer
+	}
+	if move.Demand != nil {
+		demand = *move.Demand
+	}
+
+	*m = Move{Round: move.Round, Agent: move.Agent}
+	switch move.Kind {
+	case "none":
+	case "abstain":
+		m.Message = message.TradeAbstain{}
+	case "request":
+		m.Message = message.TradeRequest{CounterPartyID: move.CounterPartyID, Offer: offer, Demand: demand}
+	case "accept":
+		m.Message = message.TradeAccept{TradeID: move.TradeID}
+	case "reject":
+		m.Message = message.TradeReject{TradeID: move.TradeID}
+	case "bargain":
+		m.Message = message.TradeBargain{TradeID: move.TradeID, Offer: offer, Demand: demand}
+	default:
+		return fmt.Errorf("unknown trade message kind %q", move.Kind)
+	}
+	return nil
+}
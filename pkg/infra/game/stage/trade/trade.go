@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"infra/game/agent"
 	"infra/game/commons"
+	"infra/game/decision"
 	"infra/game/message"
 	"infra/game/stage/trade/internal"
 	"infra/game/state"
 	"infra/logging"
-	"time"
+
+	"github.com/benbjohnson/immutable"
 )
 
 // HandleTrade
@@ -17,19 +19,11 @@ import (
 // 1. Each agent can respond to one of the trading negotiations it is involved in OR propose a new trade to another agent.
 // 2. Main thread collects trade messages from all agents, and updated the state accordingly.
 // 3. Collected message will be forwarded to corresponding target agents in the start of next round.
-func HandleTrade(s state.State, agents map[commons.ID]agent.Agent, round uint, roundLimit uint) {
-	// track offers made by each agent, no repeated offers are allowed
-	// i.e. only one offer of a specific item from an agent to another agent is allowed to exist simultaneously
-	availableWeapons := make(map[commons.ID][]state.Item)
-	availableShields := make(map[commons.ID][]state.Item)
-	// track all ongoing negotiations
-	negotiations := make(map[commons.TradeID]message.TradeNegotiation)
-	info := internal.NewInfo(negotiations, *internal.NewInventory(availableWeapons, availableShields))
-	// extract inventory from agents
-	for agentID, agentState := range s.AgentState {
-		info.Inventory.Weapons()[agentID] = commons.ImmutableListToSlice(agentState.Weapons)
-		info.Inventory.Shields()[agentID] = commons.ImmutableListToSlice(agentState.Shields)
-	}
+// The messages are returned in the order they were handled, so that the stage can be replayed with ReplayTrade,
+// along with the negotiations whose trades went through.
+func HandleTrade(s state.State, agents map[commons.ID]agent.Agent, round uint, roundLimit uint) ([]Move, []message.TradeNegotiation) {
+	info := newInfo(s)
+	moves := make([]Move, 0)
 
 	for r := uint(0); r < round; r++ {
 		starts := make(map[commons.ID]chan interface{})
@@ -51,42 +45,114 @@ func HandleTrade(s state.State, agents map[commons.ID]agent.Agent, round uint, r
 		for _, startMessage := range starts {
 			startMessage <- nil
 		}
-		// handle responses from agents
-		for agentID, response := range responses {
-			negotiation := <-response
+		// handle responses from agents in ID order, so that conflicting responses resolve the same way every run;
+		// every agent has answered once this loop is done, so the round can be closed straight away
+		for _, agentID := range commons.SortedKeys(responses) {
+			negotiation := <-responses[agentID]
+			moves = append(moves, Move{Round: r, Agent: agentID, Message: negotiation})
 			HandleTradeMessage(agentID, negotiation, info, s.AgentState)
 		}
-		// timeout for agents to respond
-		time.Sleep(100 * time.Millisecond)
 		for id, closure := range closures {
 			closure <- nil
 			close(closure)
 			close(starts[id])
 			close(responses[id])
 		}
-		// filter out outdated negotiations
-		for id, negotiation := range negotiations {
-			negotiation.RoundNum++
-			if negotiation.RoundNum > roundLimit {
-				logging.Log(logging.Trace, nil, fmt.Sprintf("Negotiation %s between %s and %s is outdated", id, negotiation.Agent1, negotiation.Agent2))
-				delete(negotiations, id)
-			} else {
-				negotiations[id] = negotiation
+		endRound(r, roundLimit, info)
+	}
+
+	settle(&s, agents, info)
+	return moves, info.Executed()
+}
+
+// ReplayTrade runs a trade stage with the recorded moves in place of the agents' messages,
+// returning the negotiations whose trades went through.
+func ReplayTrade(s state.State, agents map[commons.ID]agent.Agent, round uint, roundLimit uint, moves []Move) []message.TradeNegotiation {
+	info := newInfo(s)
+	for r := uint(0); r < round; r++ {
+		for _, move := range moves {
+			if move.Round == r {
+				HandleTradeMessage(move.Agent, move.Message, info, s.AgentState)
 			}
 		}
-		logging.Log(logging.Info, logging.LogField{
-			"round":          r,
-			"numNegotiation": len(negotiations),
-		}, fmt.Sprintf("Round %d: %d ongoing negotiations", r, len(negotiations)))
+		endRound(r, roundLimit, info)
 	}
+	settle(&s, agents, info)
+	return info.Executed()
+}
 
-	// End of trade stage, update agent inventory
-	for agentID := range agents {
+// newInfo tracks offers made by each agent, no repeated offers are allowed
+// i.e. only one offer of a specific item from an agent to another agent is allowed to exist simultaneously
+func newInfo(s state.State) *internal.Info {
+	availableWeapons := make(map[commons.ID][]state.Item)
+	availableShields := make(map[commons.ID][]state.Item)
+	// track all ongoing negotiations
+	negotiations := make(map[commons.TradeID]message.TradeNegotiation)
+	info := internal.NewInfo(negotiations, *internal.NewInventory(availableWeapons, availableShields))
+	// extract inventory from agents
+	for agentID, agentState := range s.AgentState {
+		info.Inventory.Weapons()[agentID] = commons.ImmutableListToSlice(agentState.Weapons)
+		info.Inventory.Shields()[agentID] = commons.ImmutableListToSlice(agentState.Shields)
+	}
+	return info
+}
+
+// endRound filters out outdated negotiations
+func endRound(r uint, roundLimit uint, info *internal.Info) {
+	negotiations := info.Negotiations()
+	for id, negotiation := range negotiations {
+		negotiation.RoundNum++
+		if negotiation.RoundNum > roundLimit {
+			logging.Log(logging.Trace, nil, fmt.Sprintf("Negotiation %s between %s and %s is outdated", id, negotiation.Agent1, negotiation.Agent2))
+			delete(negotiations, id)
+		} else {
+			negotiations[id] = negotiation
+		}
+	}
+	logging.Log(logging.Info, logging.LogField{
+		"round":          r,
+		"numNegotiation": len(negotiations),
+	}, fmt.Sprintf("Round %d: %d ongoing negotiations", r, len(negotiations)))
+}
+
+// settle ends the trade stage, updating agent inventory. Every traded item is taken from its old owner before any
+// is given to its new one, so that InventoryMap keeps track of it.
+func settle(s *state.State, agents map[commons.ID]agent.Agent, info *internal.Info) {
+	ids := commons.SortedKeys(agents)
+	for _, agentID := range ids {
 		agentState := s.AgentState[agentID]
-		agentState.Weapons = *commons.SliceToImmutableList(availableWeapons[agentID])
-		agentState.Shields = *commons.SliceToImmutableList(availableShields[agentID])
-		s.AgentState[agentID] = agentState
+		for _, item := range lostItems(agentState.Weapons, info.Weapons()[agentID]) {
+			s.TakeItem(agentID, commons.Weapon, item.Id())
+		}
+		for _, item := range lostItems(agentState.Shields, info.Shields()[agentID]) {
+			s.TakeItem(agentID, commons.Shield, item.Id())
+		}
+	}
+	for _, agentID := range ids {
+		for _, item := range info.Weapons()[agentID] {
+			s.GiveItem(agentID, commons.Weapon, item)
+		}
+		for _, item := range info.Shields()[agentID] {
+			s.GiveItem(agentID, commons.Shield, item)
+		}
+	}
+}
+
+// lostItems returns the items of inventory that are not in traded.
+func lostItems(inventory immutable.List[state.Item], traded []state.Item) []state.Item {
+	kept := make(map[commons.ItemID]bool, len(traded))
+	for _, item := range traded {
+		kept[item.Id()] = true
+	}
+	var lost []state.Item
+	iterator := inventory.Iterator()
+	for !iterator.Done() {
+		_, item := iterator.Next()
+		if !kept[item.Id()] {
+			lost = append(lost, item)
+		}
 	}
+	return lost
 }
 
 func NewTradeInfo(agentID commons.ID, info *internal.Info) message.TradeInfo {
@@ -114,7 +180,7 @@ func HandleTradeRequest(agentID commons.ID, msg message.TradeRequest,
 	info *internal.Info,
 ) {
 	// add new negotiation to ongoing negotiations
-	negotiation := message.NewTradeNegotiation(agentID, msg.CounterPartyID, msg.Offer, msg.Demand)
+	negotiation := message.NewTradeNegotiation(info.NextTradeID(), agentID, msg.CounterPartyID, msg.Offer, msg.Demand)
 	info.Negotiations()[negotiation.Id] = negotiation
 	// remove offered item from available items
 	if msg.Offer.ItemType == commons.Weapon {
@@ -133,6 +199,7 @@ func HandleTradeResponse(agentID commons.ID, msg message.TradeResponse,
 		negotiation := info.Negotiations()[resp.TradeID]
 		if negotiation.Notarize(agentState) {
 			ExecuteTrade(&info.Inventory, negotiation)
+			info.Execute(negotiation)
 		}
 		RemoveFromNegotiation(resp.TradeID, agentID, info.Negotiations())
 	case message.TradeReject:
@@ -261,3 +328,24 @@ func FindNegotiations(agentID commons.ID, negotiations map[commons.TradeID]messa
 	}
 	return result
 }
+
+// Tax takes the given percentage of the value of each item received in an executed trade off the health of the
+// agent receiving it, into the HP pool. It returns the tax each agent paid.
+func Tax(s *state.State, negotiation message.TradeNegotiation, percent uint) map[commons.ID]uint {
+	paid := make(map[commons.ID]uint)
+	for _, received := range []struct {
+		agent commons.ID
+		offer message.TradeOffer
+	}{
+		{negotiation.Agent1, negotiation.Condition2.Offer},
+		{negotiation.Agent2, negotiation.Condition1.Offer},
+	} {
+		if !received.offer.IsValid {
+			continue
+		}
+		if tax := s.Levy(received.agent, decision.Share(received.offer.Item.Value(), percent), state.CauseTax); tax > 0 {
+			paid[received.agent] += tax
+		}
+	}
+	return paid
+}
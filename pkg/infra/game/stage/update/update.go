@@ -19,20 +19,22 @@ func UpdateInternalStates(agentMap map[commons.ID]agent.Agent, globalState *stat
 		a := a
 		wg.Add(1)
 		go func(wait *sync.WaitGroup) {
-			a.HandleUpdateInternalState(globalState.AgentState[id], immutableFightRounds, votesResult, agentLogChan)
+			a.HandleUpdateInternalState(globalState.AgentState[id], globalState.Delta(id), immutableFightRounds, votesResult, agentLogChan)
 			wait.Done()
 		}(&wg)
 	}
 
 	agentLogs := make(map[commons.ID]logging.AgentLog)
+	collected := make(chan struct{})
 	go func(agentLogChan chan logging.AgentLog, agentLogs map[commons.ID]logging.AgentLog) {
 		for log := range agentLogChan {
 			agentLogs[log.ID] = log
 		}
+		close(collected)
 	}(agentLogChan, agentLogs)
 	wg.Wait()
-	// fmt.Println(agentLogs)
 	close(agentLogChan)
-	// fmt.Println(agentLogs)
+	// wait for the last log to be stored before handing the map over
+	<-collected
 	return agentLogs
 }
@@ -0,0 +1,134 @@
+package state
+
+import (
+	"encoding/json"
+
+	"infra/game/commons"
+	"infra/game/decision"
+)
+
+// The state is saved in game checkpoints as JSON. The methods below let encoding/json see the unexported
+// fields of items and defectors and the contents of the agents' immutable inventories and of the fight history.
+
+type itemJSON struct {
+	ID    commons.ItemID
+	Value uint
+}
+
+func (i Item) MarshalJSON() ([]byte, error) {
+	return json.Marshal(itemJSON{ID: i.id, Value: i.value})
+}
+
+func (i *Item) UnmarshalJSON(data []byte) error {
+	var item itemJSON
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+	*i = Item{id: item.ID, value: item.Value}
+	return nil
+}
+
+type defectorJSON struct {
+	Fight bool
+	Loot  bool
+}
+
+func (d Defector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(defectorJSON{Fight: d.fight, Loot: d.loot})
+}
+
+func (d *Defector) UnmarshalJSON(data []byte) error {
+	var defector defectorJSON
+	if err := json.Unmarshal(data, &defector); err != nil {
+		return err
+	}
+	*d = Defector{fight: defector.Fight, loot: defector.Loot}
+	return nil
+}
+
+type agentStateJSON struct {
+	Hp          uint
+	Stamina     uint
+	Attack      uint
+	Defense     uint
+	WeaponInUse commons.ItemID
+	ShieldInUse commons.ItemID
+	Weapons     []Item
+	Shields     []Item
+	Defector    Defector
+}
+
+func (s AgentState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(agentStateJSON{
+		Hp:          s.Hp,
+		Stamina:     s.Stamina,
+		Attack:      s.Attack,
+		Defense:     s.Defense,
+		WeaponInUse: s.WeaponInUse,
+		ShieldInUse: s.ShieldInUse,
+		Weapons:     commons.ImmutableListToSlice(s.Weapons),
+		Shields:     commons.ImmutableListToSlice(s.Shields),
+		Defector:    s.Defector,
+	})
+}
+
+func (s *AgentState) UnmarshalJSON(data []byte) error {
+	var agentState agentStateJSON
+	if err := json.Unmarshal(data, &agentState); err != nil {
+		return err
+	}
+	*s = AgentState{
+		Hp:          agentState.Hp,
+		Stamina:     agentState.Stamina,
+		Attack:      agentState.Attack,
+		Defense:     agentState.Defense,
+		WeaponInUse: agentState.WeaponInUse,
+		ShieldInUse: agentState.ShieldInUse,
+		Weapons:     *commons.SliceToImmutableList(agentState.Weapons),
+		Shields:     *commons.SliceToImmutableList(agentState.Shields),
+		Defector:    agentState.Defector,
+	}
+	return nil
+}
+
+type roundRecordJSON struct {
+	Round         uint
+	Choices       map[commons.ID]decision.FightAction
+	Targets       map[commons.ID]decision.MonsterIdx
+	AttackSum     uint
+	ShieldSum     uint
+	Damage        map[commons.ID]uint
+	Deaths        []commons.ID
+	MonsterHealth uint
+}
+
+func (r RoundRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(roundRecordJSON{
+		Round:         r.Round,
+		Choices:       commons.ImmutableToMap(r.Choices),
+		Targets:       commons.ImmutableToMap(r.Targets),
+		AttackSum:     r.AttackSum,
+		ShieldSum:     r.ShieldSum,
+		Damage:        commons.ImmutableToMap(r.Damage),
+		Deaths:        commons.ImmutableListToSlice(r.Deaths),
+		MonsterHealth: r.MonsterHealth,
+	})
+}
+
+func (r *RoundRecord) UnmarshalJSON(data []byte) error {
+	var round roundRecordJSON
+	if err := json.Unmarshal(data, &round); err != nil {
+		return err
+	}
+	*r = RoundRecord{
+		Round:         round.Round,
+		Choices:       commons.MapToImmutable(round.Choices),
+		Targets:       commons.MapToImmutable(round.Targets),
+		AttackSum:     round.AttackSum,
+		ShieldSum:     round.ShieldSum,
+		Damage:        commons.MapToImmutable(round.Damage),
+		Deaths:        commons.ListToImmutableList(round.Deaths),
+		MonsterHealth: round.MonsterHealth,
+	}
+	return nil
+}
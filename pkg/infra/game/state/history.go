@@ -0,0 +1,69 @@
+package state
+
+import (
+	"infra/game/commons"
+	"infra/game/decision"
+
+	"github.com/benbjohnson/immutable"
+)
+
+// RoundRecord is what happened in one of a level's fight rounds.
+type RoundRecord struct {
+	Round uint
+	// Choices are the actions the agents took, and Targets the monsters those that attacked or defended fought
+	Choices   immutable.Map[commons.ID, decision.FightAction]
+	Targets   immutable.Map[commons.ID, decision.MonsterIdx]
+	AttackSum uint
+	ShieldSum uint
+	// Damage is the health each agent hit by the monsters lost, and Deaths the agents killed in the round
+	Damage immutable.Map[commons.ID, uint]
+	Deaths immutable.List[commons.ID]
+	// MonsterHealth is the health the monsters had left at the end of the round
+	MonsterHealth uint
+}
+
+// LevelFights are the fight rounds of a level, in order.
+type LevelFights struct {
+	Level  uint
+	Rounds []RoundRecord
+}
+
+// FightHistory is what happened in the fight rounds of the current level and of the levels before it.
+type FightHistory struct {
+	Current LevelFights
+	// Past are the levels before the current one, oldest first
+	Past []LevelFights
+}
+
+// RecordRound adds a round to the fight history of the current level.
+func (s *State) RecordRound(round RoundRecord) {
+	s.FightHistory.Current.Rounds = append(s.FightHistory.Current.Rounds, round)
+}
+
+// StartFightHistory starts the fight history of the current level, keeping that of at most keep levels before it.
+func (s *State) StartFightHistory(keep uint) {
+	history := &s.FightHistory
+	// a new slice is made rather than appending in place, as views taken earlier share the old one
+	past := append([]LevelFights(nil), history.Past...)
+	if history.Current.Level != 0 {
+		past = append(past, history.Current)
+	}
+	if uint(len(past)) > keep {
+		past = past[uint(len(past))-keep:]
+	}
+	history.Past = past
+	history.Current = LevelFights{Level: s.CurrentLevel}
+}
+
+// copy returns a copy of the history that shares no slices with it. The rounds themselves are immutable.
+func (h FightHistory) copy() FightHistory {
+	c := FightHistory{Current: h.Current.copy(), Past: make([]LevelFights, len(h.Past))}
+	for i, level := range h.Past {
+		c.Past[i] = level.copy()
+	}
+	return c
+}
+
+func (l LevelFights) copy() LevelFights {
+	return LevelFights{Level: l.Level, Rounds: append([]RoundRecord(nil), l.Rounds...)}
+}
@@ -0,0 +1,35 @@
+package state_test
+
+import (
+	"testing"
+
+	"infra/game/state"
+)
+
+func TestFightHistory(t *testing.T) {
+	t.Parallel()
+
+	s := &state.State{}
+	for level := uint(1); level <= 4; level++ {
+		s.CurrentLevel = level
+		s.StartFightHistory(2)
+		for round := uint(0); round < level; round++ {
+			s.RecordRound(state.RoundRecord{Round: round})
+		}
+	}
+
+	view := s.ToView()
+	if rounds := view.FightRounds(); len(rounds) != 4 {
+		t.Errorf("FightRounds() has %d rounds; want the 4 of the current level", len(rounds))
+	}
+	history := view.FightHistory()
+	if len(history.Past) != 2 || history.Past[0].Level != 2 || history.Past[1].Level != 3 || len(history.Past[1].Rounds) != 3 {
+		t.Errorf("FightHistory().Past = %+v; want levels 2 and 3", history.Past)
+	}
+
+	history.Past[0].Rounds[0].Round = 9
+	s.RecordRound(state.RoundRecord{Round: 4})
+	if rounds := view.FightRounds(); len(rounds) != 4 || view.FightHistory().Past[0].Rounds[0].Round != 0 {
+		t.Error("the view's fight history changed after it was taken")
+	}
+}
@@ -0,0 +1,68 @@
+package state
+
+import (
+	"infra/game/decision"
+	"infra/game/monster"
+)
+
+// MonsterState is one of the monsters of a level, of the type and with the ability given by its Monster.
+type MonsterState struct {
+	monster.Monster
+	Health uint
+	Attack uint
+}
+
+// Monsters are the monsters of a level, indexed by decision.MonsterIdx.
+type Monsters []MonsterState
+
+// Health returns the health the monsters have left between them.
+func (m Monsters) Health() uint {
+	health := uint(0)
+	for _, monster := range m {
+		health += monster.Health
+	}
+	return health
+}
+
+// Attack returns the combined attack of the monsters still alive.
+func (m Monsters) Attack() uint {
+	attack := uint(0)
+	for _, monster := range m {
+		if monster.Health > 0 {
+			attack += monster.Attack
+		}
+	}
+	return attack
+}
+
+// Target returns the monster an agent aiming at target fights: the target if it is still alive,
+// or else the first monster that is.
+func (m Monsters) Target(target decision.MonsterIdx) decision.MonsterIdx {
+	if int(target) < len(m) && m[target].Health > 0 {
+		return target
+	}
+	for i, monster := range m {
+		if monster.Health > 0 {
+			return decision.MonsterIdx(i)
+		}
+	}
+	return 0
+}
+
+// Lead returns the first monster still alive, or the first monster once they are all slain.
+func (m Monsters) Lead() MonsterState {
+	if len(m) == 0 {
+		return MonsterState{}
+	}
+	return m[m.Target(0)]
+}
+
+// MonsterHealth returns the health the level's monsters have left between them.
+func (s *State) MonsterHealth() uint {
+	return s.Monsters.Health()
+}
+
+// MonsterAttack returns the combined attack of the level's monsters still alive.
+func (s *State) MonsterAttack() uint {
+	return s.Monsters.Attack()
+}
@@ -0,0 +1,37 @@
+package state_test
+
+import (
+	"testing"
+
+	"infra/game/decision"
+	"infra/game/state"
+)
+
+func TestMonsters(t *testing.T) {
+	t.Parallel()
+
+	monsters := state.Monsters{{Health: 0, Attack: 10}, {Health: 5, Attack: 20}, {Health: 7, Attack: 30}}
+	if health := monsters.Health(); health != 12 {
+		t.Errorf("Health() = %d; want 12", health)
+	}
+	if attack := monsters.Attack(); attack != 50 {
+		t.Errorf("Attack() = %d; want the 50 of the monsters still alive", attack)
+	}
+
+	tests := []struct {
+		target decision.MonsterIdx
+		want   decision.MonsterIdx
+	}{
+		{2, 2},
+		{0, 1},
+		{9, 1},
+	}
+	for _, tt := range tests {
+		if got := monsters.Target(tt.target); got != tt.want {
+			t.Errorf("Target(%d) = %d; want %d", tt.target, got, tt.want)
+		}
+	}
+	if lead := monsters.Lead(); lead.Attack != 20 {
+		t.Errorf("Lead() = %+v; want the first monster still alive", lead)
+	}
+}
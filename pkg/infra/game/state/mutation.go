@@ -0,0 +1,268 @@
+package state
+
+import (
+	"infra/game/commons"
+
+	"github.com/benbjohnson/immutable"
+)
+
+// Cause says why an agent's state was changed.
+type Cause string
+
+const (
+	// CauseFight is damage dealt by the monster.
+	CauseFight Cause = "fight"
+	// CauseAttack and CauseDefend are the stamina spent attacking and defending.
+	CauseAttack Cause = "attack"
+	CauseDefend Cause = "defend"
+	// CauseCower is the health and stamina recovered by cowering.
+	CauseCower Cause = "cower"
+	// CauseAbility is a change made by the monster's ability.
+	CauseAbility Cause = "ability"
+	// CausePotion is the health or stamina given by a potion allocated as loot.
+	CausePotion Cause = "potion"
+	// CauseDonation is the health given to the HP pool.
+	CauseDonation Cause = "donation"
+	// CauseRegen is the stamina recovered at the end of each fight round and level.
+	CauseRegen Cause = "regen"
+	// CauseTax and CauseFine are the health the leader's policies take into the HP pool, as a trade tax or as
+	// the fine of a defector.
+	CauseTax  Cause = "tax"
+	CauseFine Cause = "fine"
+)
+
+// Delta sums up the changes the State methods below made to an agent's state over a level.
+type Delta struct {
+	// Hp and Stamina are the net changes to the agent's health and stamina, by their cause
+	Hp          map[Cause]int    `json:",omitempty"`
+	Stamina     map[Cause]int    `json:",omitempty"`
+	ItemsGained []commons.ItemID `json:",omitempty"`
+	ItemsLost   []commons.ItemID `json:",omitempty"`
+	// Died is the cause of the agent's death, empty if it is alive
+	Died Cause `json:",omitempty"`
+}
+
+// The methods below are the way to change the state of the agents. They preserve everything they are not asked
+// to change, keep InventoryMap in step with the agents' inventories, remove an agent and its items once it dies,
+// do nothing to agents that are not alive, and record what they did in the level's deltas.
+// An agent removed from the state must also be removed from the game's agent map by the caller.
+
+// TrackDeltas starts recording the changes made to the agents afresh, at the start of a level.
+func (s *State) TrackDeltas() {
+	s.deltas = make(map[commons.ID]*Delta)
+}
+
+// Deltas returns the changes made to each agent since TrackDeltas was last called.
+func (s *State) Deltas() map[commons.ID]Delta {
+	deltas := make(map[commons.ID]Delta, len(s.deltas))
+	for id, delta := range s.deltas {
+		deltas[id] = delta.copy()
+	}
+	return deltas
+}
+
+// Delta returns the changes made to the agent since TrackDeltas was last called.
+func (s *State) Delta(id commons.ID) Delta {
+	if delta, ok := s.deltas[id]; ok {
+		return delta.copy()
+	}
+	return Delta{}
+}
+
+func (d *Delta) copy() Delta {
+	c := Delta{Died: d.Died}
+	if d.Hp != nil {
+		c.Hp = make(map[Cause]int, len(d.Hp))
+		for cause, change := range d.Hp {
+			c.Hp[cause] = change
+		}
+	}
+	if d.Stamina != nil {
+		c.Stamina = make(map[Cause]int, len(d.Stamina))
+		for cause, change := range d.Stamina {
+			c.Stamina[cause] = change
+		}
+	}
+	c.ItemsGained = append(c.ItemsGained, d.ItemsGained...)
+	c.ItemsLost = append(c.ItemsLost, d.ItemsLost...)
+	return c
+}
+
+// Damage takes up to amount off the agent's health, killing it if none is left. It reports whether the agent died.
+func (s *State) Damage(id commons.ID, amount uint, cause Cause) (killed bool) {
+	agentState, ok := s.AgentState[id]
+	if !ok || amount == 0 {
+		return false
+	}
+	amount = minUint(amount, agentState.Hp)
+	agentState.Hp -= amount
+	s.AgentState[id] = agentState
+	s.recordHp(id, -int(amount), cause)
+	if agentState.Hp == 0 {
+		s.Kill(id, cause)
+		return true
+	}
+	return false
+}
+
+// Levy moves up to amount of the agent's health into the HP pool, always leaving it alive.
+// It returns the health taken.
+func (s *State) Levy(id commons.ID, amount uint, cause Cause) uint {
+	agentState, ok := s.AgentState[id]
+	if !ok || agentState.Hp == 0 {
+		return 0
+	}
+	amount = minUint(amount, agentState.Hp-1)
+	s.Damage(id, amount, cause)
+	s.HpPool += amount
+	return amount
+}
+
+// Heal adds amount to the agent's health.
+func (s *State) Heal(id commons.ID, amount uint, cause Cause) {
+	agentState, ok := s.AgentState[id]
+	if !ok || amount == 0 {
+		return
+	}
+	agentState.Hp += amount
+	s.AgentState[id] = agentState
+	s.recordHp(id, int(amount), cause)
+}
+
+// SpendStamina takes up to amount off the agent's stamina.
+func (s *State) SpendStamina(id commons.ID, amount uint, cause Cause) {
+	agentState, ok := s.AgentState[id]
+	if !ok {
+		return
+	}
+	amount = minUint(amount, agentState.Stamina)
+	if amount == 0 {
+		return
+	}
+	agentState.Stamina -= amount
+	s.AgentState[id] = agentState
+	s.recordStamina(id, -int(amount), cause)
+}
+
+// GainStamina adds amount to the agent's stamina.
+func (s *State) GainStamina(id commons.ID, amount uint, cause Cause) {
+	agentState, ok := s.AgentState[id]
+	if !ok || amount == 0 {
+		return
+	}
+	agentState.Stamina += amount
+	s.AgentState[id] = agentState
+	s.recordStamina(id, int(amount), cause)
+}
+
+// GiveItem adds the item to the agent's inventory, unless the agent already has it.
+func (s *State) GiveItem(id commons.ID, itemType commons.ItemType, item Item) {
+	agentState, ok := s.AgentState[id]
+	if !ok || agentState.HasItem(itemType, item.Id()) {
+		return
+	}
+	if itemType == commons.Weapon {
+		agentState.AddWeapon(item)
+		s.InventoryMap.Weapons[item.Id()] = item.Value()
+	} else {
+		agentState.AddShield(item)
+		s.InventoryMap.Shields[item.Id()] = item.Value()
+	}
+	s.AgentState[id] = agentState
+	if delta := s.delta(id); delta != nil {
+		delta.ItemsGained = append(delta.ItemsGained, item.Id())
+	}
+}
+
+// TakeItem removes the item from the agent's inventory, unequipping it if it was in use, and returns it.
+func (s *State) TakeItem(id commons.ID, itemType commons.ItemType, itemID commons.ItemID) (Item, bool) {
+	agentState, ok := s.AgentState[id]
+	if !ok || !agentState.HasItem(itemType, itemID) {
+		return Item{}, false
+	}
+	var item Item
+	if itemType == commons.Weapon {
+		item = *NewItem(itemID, s.InventoryMap.Weapons[itemID])
+		agentState.RemoveWeapon(itemID)
+		delete(s.InventoryMap.Weapons, itemID)
+	} else {
+		item = *NewItem(itemID, s.InventoryMap.Shields[itemID])
+		agentState.RemoveShield(itemID)
+		delete(s.InventoryMap.Shields, itemID)
+	}
+	s.AgentState[id] = agentState
+	if delta := s.delta(id); delta != nil {
+		delta.ItemsLost = append(delta.ItemsLost, itemID)
+	}
+	return item, true
+}
+
+// Kill removes the agent, and its items, from the game.
+func (s *State) Kill(id commons.ID, cause Cause) {
+	agentState, ok := s.AgentState[id]
+	if !ok {
+		return
+	}
+	removeFromInventoryMap(s.InventoryMap.Weapons, agentState.Weapons)
+	removeFromInventoryMap(s.InventoryMap.Shields, agentState.Shields)
+	delete(s.AgentState, id)
+	if delta := s.delta(id); delta != nil {
+		delta.Died = cause
+	}
+}
+
+// MarkLootDefector records that the agent took other loot than it was allocated.
+func (s *State) MarkLootDefector(id commons.ID) {
+	agentState, ok := s.AgentState[id]
+	if !ok {
+		return
+	}
+	agentState.Defector.SetLoot(true)
+	s.AgentState[id] = agentState
+}
+
+func removeFromInventoryMap(inventory map[commons.ItemID]uint, items immutable.List[Item]) {
+	iterator := items.Iterator()
+	for !iterator.Done() {
+		_, item := iterator.Next()
+		delete(inventory, item.Id())
+	}
+}
+
+// delta returns the agent's delta for the level, or nil when deltas are not being tracked.
+func (s *State) delta(id commons.ID) *Delta {
+	if s.deltas == nil {
+		return nil
+	}
+	delta, ok := s.deltas[id]
+	if !ok {
+		delta = &Delta{}
+		s.deltas[id] = delta
+	}
+	return delta
+}
+
+func (s *State) recordHp(id commons.ID, change int, cause Cause) {
+	if delta := s.delta(id); delta != nil {
+		if delta.Hp == nil {
+			delta.Hp = make(map[Cause]int)
+		}
+		delta.Hp[cause] += change
+	}
+}
+
+func (s *State) recordStamina(id commons.ID, change int, cause Cause) {
+	if delta := s.delta(id); delta != nil {
+		if delta.Stamina == nil {
+			delta.Stamina = make(map[Cause]int)
+		}
+		delta.Stamina[cause] += change
+	}
+}
+
+func minUint(a, b uint) uint {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,98 @@
+package state_test
+
+import (
+	"testing"
+
+	"infra/game/commons"
+	"infra/game/state"
+)
+
+func newState() *state.State {
+	s := &state.State{
+		AgentState:   make(map[commons.ID]state.AgentState),
+		InventoryMap: state.InventoryMap{Weapons: make(map[commons.ItemID]uint), Shields: make(map[commons.ItemID]uint)},
+	}
+	agentState := state.AgentState{Hp: 10, Stamina: 5}
+	agentState.Defector.SetFight(true)
+	s.AgentState["a"] = agentState
+	s.AgentState["b"] = state.AgentState{Hp: 10, Stamina: 5}
+	s.TrackDeltas()
+	return s
+}
+
+func TestDamagePreservesState(t *testing.T) {
+	t.Parallel()
+
+	s := newState()
+	if s.Damage("a", 4, state.CauseFight) {
+		t.Fatal("Damage() killed an agent with health left")
+	}
+	agentState := s.AgentState["a"]
+	if agentState.Hp != 6 || agentState.Stamina != 5 || !agentState.Defector.IsDefector() {
+		t.Errorf("after Damage() the agent's state is %+v", agentState)
+	}
+	if hp := s.Delta("a").Hp[state.CauseFight]; hp != -4 {
+		t.Errorf("Delta().Hp[fight] = %d; want -4", hp)
+	}
+}
+
+func TestDamageKills(t *testing.T) {
+	t.Parallel()
+
+	s := newState()
+	s.GiveItem("a", commons.Weapon, *state.NewItem("sword", 3))
+	s.GiveItem("a", commons.Shield, *state.NewItem("shield", 2))
+	if !s.Damage("a", 25, state.CauseFight) {
+		t.Fatal("Damage() did not kill an agent left without health")
+	}
+	if _, ok := s.AgentState["a"]; ok {
+		t.Error("the dead agent is still in the state")
+	}
+	if len(s.InventoryMap.Weapons) != 0 || len(s.InventoryMap.Shields) != 0 {
+		t.Errorf("the dead agent's items are still in the inventory map: %+v", s.InventoryMap)
+	}
+	delta := s.Delta("a")
+	if delta.Hp[state.CauseFight] != -10 || delta.Died != state.CauseFight {
+		t.Errorf("Delta() = %+v; want 10 health lost to the fight that killed it", delta)
+	}
+}
+
+func TestTradeItem(t *testing.T) {
+	t.Parallel()
+
+	s := newState()
+	s.GiveItem("a", commons.Weapon, *state.NewItem("sword", 3))
+	agentState := s.AgentState["a"]
+	agentState.ChangeWeaponInUse(0)
+	s.AgentState["a"] = agentState
+
+	item, ok := s.TakeItem("a", commons.Weapon, "sword")
+	if !ok || item.Value() != 3 {
+		t.Fatalf("TakeItem() = %v, %v", item, ok)
+	}
+	if agentState = s.AgentState["a"]; agentState.BonusAttack() != 0 {
+		t.Error("the agent still uses the weapon taken from it")
+	}
+	s.GiveItem("b", commons.Weapon, item)
+	if agentState = s.AgentState["b"]; !agentState.HasItem(commons.Weapon, "sword") || s.InventoryMap.Weapons["sword"] != 3 {
+		t.Error("GiveItem() did not give the weapon")
+	}
+	if lost, gained := s.Delta("a").ItemsLost, s.Delta("b").ItemsGained; len(lost) != 1 || len(gained) != 1 {
+		t.Errorf("ItemsLost = %v, ItemsGained = %v; want the sword in both", lost, gained)
+	}
+}
+
+func TestMarkLootDefector(t *testing.T) {
+	t.Parallel()
+
+	s := newState()
+	s.MarkLootDefector("b")
+	if agentState := s.AgentState["b"]; !agentState.Defector.IsDefector() || agentState.Hp != 10 {
+		t.Errorf("after MarkLootDefector() the agent's state is %+v", agentState)
+	}
+	// agents that have left the game, e.g. by dying, stay out of it
+	s.MarkLootDefector("c")
+	if _, ok := s.AgentState["c"]; ok {
+		t.Errorf("MarkLootDefector() brought an unknown agent into the game")
+	}
+}
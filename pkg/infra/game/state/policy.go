@@ -0,0 +1,32 @@
+package state
+
+import (
+	"sort"
+
+	"infra/game/commons"
+	"infra/game/decision"
+)
+
+// LootOrder returns the agents in the order the loot priority serves them, breaking ties by ID.
+// Agents without a state are left out.
+func (s *State) LootOrder(priority decision.LootPriority, agents []commons.ID) []commons.ID {
+	order := make([]commons.ID, 0, len(agents))
+	for _, id := range agents {
+		if _, ok := s.AgentState[id]; ok {
+			order = append(order, id)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := s.AgentState[order[i]], s.AgentState[order[j]]
+		switch {
+		case priority == decision.LowestHealthFirst && a.Hp != b.Hp:
+			return a.Hp < b.Hp
+		case priority == decision.HighestHealthFirst && a.Hp != b.Hp:
+			return a.Hp > b.Hp
+		case priority == decision.LowestStaminaFirst && a.Stamina != b.Stamina:
+			return a.Stamina < b.Stamina
+		}
+		return order[i] < order[j]
+	})
+	return order
+}
@@ -0,0 +1,48 @@
+package state_test
+
+import (
+	"reflect"
+	"testing"
+
+	"infra/game/commons"
+	"infra/game/decision"
+	"infra/game/state"
+)
+
+func TestLootOrder(t *testing.T) {
+	t.Parallel()
+
+	s := &state.State{AgentState: map[commons.ID]state.AgentState{
+		"a": {Hp: 50, Stamina: 10},
+		"b": {Hp: 20, Stamina: 30},
+		"c": {Hp: 50, Stamina: 5},
+	}}
+	agents := []commons.ID{"c", "b", "a", "gone"}
+
+	tests := []struct {
+		priority decision.LootPriority
+		want     []commons.ID
+	}{
+		{decision.NoLootPriority, []commons.ID{"a", "b", "c"}},
+		{decision.LowestHealthFirst, []commons.ID{"b", "a", "c"}},
+		{decision.HighestHealthFirst, []commons.ID{"a", "c", "b"}},
+		{decision.LowestStaminaFirst, []commons.ID{"c", "a", "b"}},
+	}
+	for _, tt := range tests {
+		if got := s.LootOrder(tt.priority, agents); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LootOrder(%s) = %v; want %v", tt.priority, got, tt.want)
+		}
+	}
+}
+
+func TestLevy(t *testing.T) {
+	t.Parallel()
+
+	s := &state.State{HpPool: 5, AgentState: map[commons.ID]state.AgentState{"a": {Hp: 10}}}
+	if levied := s.Levy("a", 4, state.CauseTax); levied != 4 || s.HpPool != 9 {
+		t.Errorf("Levy(4) took %d into a pool of %d; want 4 into a pool of 9", levied, s.HpPool)
+	}
+	if levied := s.Levy("a", 100, state.CauseFine); levied != 5 || s.AgentState["a"].Hp != 1 {
+		t.Errorf("Levy(100) took %d, leaving %d health; want 5, leaving the agent alive on 1", levied, s.AgentState["a"].Hp)
+	}
+}
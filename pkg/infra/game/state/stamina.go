@@ -0,0 +1,25 @@
+package state
+
+import (
+	"math"
+
+	"infra/config"
+	"infra/game/decision"
+)
+
+// StaminaCost returns the stamina an agent in agentState spends to take the action under the model. The agent
+// needs more stamina than the cost to attack or defend, and cowers otherwise. Cowering costs nothing.
+func StaminaCost(model config.StaminaModel, agentState AgentState, action decision.FightAction) uint {
+	switch action {
+	case decision.Attack:
+		return model.AttackCost + itemCost(model, agentState.BonusAttack())
+	case decision.Defend:
+		return model.DefendCost + itemCost(model, agentState.BonusDefense())
+	default:
+		return 0
+	}
+}
+
+func itemCost(model config.StaminaModel, bonus uint) uint {
+	return uint(math.Ceil(float64(model.ItemWeight) * float64(bonus)))
+}
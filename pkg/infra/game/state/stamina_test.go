@@ -0,0 +1,34 @@
+package state_test
+
+import (
+	"testing"
+
+	"infra/config"
+	"infra/game/decision"
+	"infra/game/state"
+)
+
+func TestStaminaCost(t *testing.T) {
+	t.Parallel()
+
+	agentState := state.AgentState{Stamina: 100}
+	agentState.AddWeapon(*state.NewItem("sword", 15))
+	agentState.ChangeWeaponInUse(0)
+	model := config.StaminaModel{AttackCost: 5, DefendCost: 3, ItemWeight: 0.5}
+
+	tests := []struct {
+		action decision.FightAction
+		cost   uint
+	}{
+		// half the sword's bonus of 15, rounded up, on top of the base cost
+		{decision.Attack, 13},
+		// no shield in use, so only the base cost
+		{decision.Defend, 3},
+		{decision.Cower, 0},
+	}
+	for _, tt := range tests {
+		if cost := state.StaminaCost(model, agentState, tt.action); cost != tt.cost {
+			t.Errorf("StaminaCost(%v) = %d; want %d", tt.action, cost, tt.cost)
+		}
+	}
+}
@@ -1,10 +1,12 @@
 package state
 
 import (
+	"infra/config"
 	"infra/game/commons"
 	"infra/game/decision"
 
 	"github.com/benbjohnson/immutable"
+	"github.com/google/uuid"
 )
 
 type Defector struct {
@@ -95,6 +97,22 @@ func (s *AgentState) AddShield(shield Item) {
 	s.Shields = addToInventory(s.Shields, shield)
 }
 
+// RemoveWeapon takes the weapon out of the inventory, unequipping it if it was in use.
+func (s *AgentState) RemoveWeapon(weaponID commons.ItemID) {
+	s.Weapons = removeFromInventory(s.Weapons, weaponID)
+	if s.WeaponInUse == weaponID {
+		s.WeaponInUse = uuid.Nil.String()
+	}
+}
+
+// RemoveShield takes the shield out of the inventory, unequipping it if it was in use.
+func (s *AgentState) RemoveShield(shieldID commons.ItemID) {
+	s.Shields = removeFromInventory(s.Shields, shieldID)
+	if s.ShieldInUse == shieldID {
+		s.ShieldInUse = uuid.Nil.String()
+	}
+}
+
 func (s *AgentState) ChangeWeaponInUse(weaponIdx decision.ItemIdx) {
 	if int(weaponIdx) < s.Weapons.Len() {
 		s.WeaponInUse = s.Weapons.Get(int(weaponIdx)).Id()
@@ -108,13 +126,27 @@ func (s *AgentState) ChangeShieldInUse(shieldIdx decision.ItemIdx) {
 }
 
 type State struct {
-	CurrentLevel    uint
-	HpPool          uint
-	MonsterHealth   uint
-	MonsterAttack   uint
+	CurrentLevel uint
+	HpPool       uint
+	// Monsters are the monsters of the level, which keep their place once slain
+	Monsters        Monsters
 	AgentState      map[commons.ID]AgentState
 	InventoryMap    InventoryMap
 	CurrentLeader   commons.ID
 	LeaderManifesto decision.Manifesto
-	Defection       bool
+	// Deputy is the agent the leader's manifesto names to take over from it, and RunnerUp the runner-up of the
+	// election that chose it. Each is empty if there is none, or once it has taken over.
+	Deputy   commons.ID
+	RunnerUp commons.ID
+	// LastRecall is the level the last leader was recalled on, 0 if none has been
+	LastRecall uint
+	Defection  bool
+	// DamageModel is how the monster's damage is shared between the agents it hits
+	DamageModel config.DamageModel
+	// StaminaModel is what fighting costs the agents in stamina and how they recover it
+	StaminaModel config.StaminaModel
+	// FightHistory is what happened in the fight rounds of this level and of the last few levels
+	FightHistory FightHistory
+	// deltas records the changes made to each agent during the level, see TrackDeltas
+	deltas map[commons.ID]*Delta
 }
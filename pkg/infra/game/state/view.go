@@ -1,8 +1,10 @@
 package state
 
 import (
+	"infra/config"
 	"infra/game/commons"
 	"infra/game/decision"
+	"infra/game/monster"
 
 	"github.com/benbjohnson/immutable"
 )
@@ -10,11 +12,15 @@ import (
 type View struct {
 	currentLevel    uint
 	hpPool          uint
-	monsterHealth   uint
-	monsterAttack   uint
+	monsters        Monsters
 	agentState      *immutable.Map[commons.ID, HiddenAgentState]
 	currentLeader   commons.ID
 	leaderManifesto decision.Manifesto
+	deputy          commons.ID
+	runnerUp        commons.ID
+	damageModel     config.DamageModel
+	staminaModel    config.StaminaModel
+	fightHistory    FightHistory
 }
 
 type (
@@ -52,12 +58,25 @@ func (v *View) HpPool() uint {
 	return v.hpPool
 }
 
+// MonsterHealth is the health the level's monsters have left between them.
 func (v *View) MonsterHealth() uint {
-	return v.monsterHealth
+	return v.monsters.Health()
 }
 
+// MonsterAttack is the combined attack of the level's monsters still alive.
 func (v *View) MonsterAttack() uint {
-	return v.monsterAttack
+	return v.monsters.Attack()
+}
+
+// Monster is the first of the level's monsters still alive.
+func (v *View) Monster() monster.Monster {
+	return v.monsters.Lead().Monster
+}
+
+// Monsters are the monsters of the level, each with its own health, attack, armour and ability.
+// Agents choose which to fight, see agent.Targeter.
+func (v *View) Monsters() Monsters {
+	return append(Monsters(nil), v.monsters...)
 }
 
 func (v *View) AgentState() immutable.Map[commons.ID, HiddenAgentState] {
@@ -72,6 +91,44 @@ func (v *View) LeaderManifesto() decision.Manifesto {
 	return v.leaderManifesto
 }
 
+// Deputy is the agent the leader's manifesto names to take over if the leader dies or is recalled, see
+// config.RecallDeputy. It is empty if there is none.
+func (v *View) Deputy() commons.ID {
+	return v.deputy
+}
+
+// RunnerUp is the runner-up of the election that chose the leader, who takes over if there is no deputy alive,
+// see config.Succession. It is empty if there is none.
+func (v *View) RunnerUp() commons.ID {
+	return v.runnerUp
+}
+
+// DamageModel is how the monster's damage is shared between the agents it hits, see config.DamageModel.
+func (v *View) DamageModel() config.DamageModel {
+	return v.damageModel
+}
+
+// StaminaModel is what fighting costs the agents in stamina and how they recover it, see config.StaminaModel.
+func (v *View) StaminaModel() config.StaminaModel {
+	return v.staminaModel
+}
+
+// StaminaCost returns the stamina an agent in agentState would spend to take the action, see StaminaCost.
+func (v *View) StaminaCost(agentState AgentState, action decision.FightAction) uint {
+	return StaminaCost(v.staminaModel, agentState, action)
+}
+
+// FightRounds are the rounds fought so far on the current level, in order.
+func (v *View) FightRounds() []RoundRecord {
+	return v.fightHistory.Current.copy().Rounds
+}
+
+// FightHistory is what happened in the fight rounds of the current level and of the last few levels, as many as
+// config.GameConfig.FightHistoryLevels.
+func (v *View) FightHistory() FightHistory {
+	return v.fightHistory.copy()
+}
+
 func (s *State) ToView() View {
 	b := immutable.NewMapBuilder[commons.ID, HiddenAgentState](nil)
 
@@ -106,10 +163,14 @@ func (s *State) ToView() View {
 	return View{
 		currentLevel:    s.CurrentLevel,
 		hpPool:          s.HpPool,
-		monsterHealth:   s.MonsterHealth,
-		monsterAttack:   s.MonsterAttack,
+		monsters:        append(Monsters(nil), s.Monsters...),
 		agentState:      b.Map(),
 		currentLeader:   s.CurrentLeader,
 		leaderManifesto: s.LeaderManifesto,
+		deputy:          s.Deputy,
+		runnerUp:        s.RunnerUp,
+		damageModel:     s.DamageModel,
+		staminaModel:    s.StaminaModel,
+		fightHistory:    s.FightHistory,
 	}
 }
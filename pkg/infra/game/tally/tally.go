@@ -1,6 +1,8 @@
 package tally
 
 import (
+	"sort"
+
 	"infra/game/commons"
 	"infra/game/decision"
 	"infra/game/message"
@@ -11,7 +13,8 @@ import (
 type Tally[A decision.ProposalAction] struct {
 	proposalTally map[commons.ProposalID]uint
 	proposalMap   map[commons.ProposalID]commons.ImmutableList[proposal.Rule[A]]
-	currMax       internal.VoteCount
+	proposers     map[commons.ProposalID]commons.ID
+	targets       map[commons.ProposalID]decision.MonsterIdx
 	votes         <-chan commons.ProposalID
 	proposals     <-chan message.Proposal[A]
 	closure       <-chan struct{}
@@ -25,6 +28,11 @@ func (t *Tally[A]) ProposalMap() map[commons.ProposalID]commons.ImmutableList[pr
 	return t.proposalMap
 }
 
+// Proposer returns the agent that made the proposal.
+func (t *Tally[A]) Proposer(id commons.ProposalID) commons.ID {
+	return t.proposers[id]
+}
+
 func NewTally[A decision.ProposalAction](votes <-chan commons.ProposalID,
 	proposals <-chan message.Proposal[A],
 	closure <-chan struct{},
@@ -32,6 +40,8 @@ func NewTally[A decision.ProposalAction](votes <-chan commons.ProposalID,
 	return &Tally[A]{
 		proposalTally: make(map[commons.ProposalID]uint),
 		proposalMap:   make(map[commons.ProposalID]commons.ImmutableList[proposal.Rule[A]]),
+		proposers:     make(map[commons.ProposalID]commons.ID),
+		targets:       make(map[commons.ProposalID]decision.MonsterIdx),
 		votes:         votes,
 		proposals:     proposals,
 		closure:       closure,
@@ -43,21 +53,44 @@ func (t *Tally[A]) HandleMessages() {
 	for {
 		select {
 		case p := <-t.proposals:
+			t.see(p.ProposalID())
 			t.proposalMap[p.ProposalID()] = p.Rules()
-			t.proposalTally[p.ProposalID()] = 0
+			t.proposers[p.ProposalID()] = p.ProposerID()
+			if target, ok := p.Target(); ok {
+				t.targets[p.ProposalID()] = target
+			}
 		case vote := <-t.votes:
+			t.see(vote)
 			t.proposalTally[vote]++
-			if t.currMax.Count < t.proposalTally[vote] {
-				t.currMax.ID = vote
-				t.currMax.Count = t.proposalTally[vote]
-			}
 		case <-t.closure:
 			return
 		}
 	}
 }
 
+func (t *Tally[A]) see(id commons.ProposalID) {
+	if _, ok := t.proposalTally[id]; !ok {
+		t.proposalTally[id] = 0
+	}
+}
+
 // GetMax call from thread after goroutine closes.
+// Returns the proposal with the most votes. Proposals and votes arrive in whatever order the agents' goroutines
+// send them, so ties go to the proposal of the lowest proposer ID, then the lowest proposal ID.
 func (t *Tally[A]) GetMax() message.Proposal[A] {
-	return *message.NewProposalInternal[A](t.currMax.ID, t.proposalMap[t.currMax.ID])
+	ids := commons.SortedKeys(t.proposalTally)
+	sort.SliceStable(ids, func(i, j int) bool {
+		return t.proposers[ids[i]] < t.proposers[ids[j]]
+	})
+	var currMax internal.VoteCount
+	for _, id := range ids {
+		if t.proposalTally[id] > currMax.Count {
+			currMax = internal.VoteCount{ID: id, Count: t.proposalTally[id]}
+		}
+	}
+	winner := message.NewProposalInternal[A](currMax.ID, t.proposalMap[currMax.ID])
+	if target, ok := t.targets[currMax.ID]; ok {
+		winner = winner.WithTarget(&target)
+	}
+	return *winner
 }
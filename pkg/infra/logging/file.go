@@ -16,9 +16,6 @@ import (
 // 	HPPool
 // )
 
-var fileLog GameLog
-var runID string
-
 type Outcome bool
 
 const (
@@ -32,16 +29,24 @@ const (
 	Default Mode = "default"
 )
 
+// VotingStrategy takes the values of decision.VotingStrategy.
 type VotingStrategy uint
 
 const (
-	SingleChoicePlurality VotingStrategy = 1
-	BordaCount            VotingStrategy = 2
+	SingleChoicePlurality VotingStrategy = iota
+	BordaCount
+	InstantRunoff
+	Approval
+	Copeland
+	Schulze
+	TwoRoundRunoff
 )
 
 type GameLog struct {
 	Warnings []LogField
 	Errors   []LogField
+	// Timeouts counts, per agent ID, the strategy calls that overran their decision budget
+	Timeouts map[commons.ID]uint
 	Outcome  Outcome
 	Config   Config
 	Levels   []LevelStages
@@ -57,6 +62,8 @@ type Config struct {
 	PassThreshold     float32
 	VotingStrategy    VotingStrategy
 	VotingPreferences uint
+	TieBreak          string
+	AnonymousBallots  bool
 	AgentRandomQty    uint
 	AgentTeam1Qty     uint
 	AgentTeam2Qty     uint
@@ -64,6 +71,36 @@ type Config struct {
 	AgentTeam4Qty     uint
 	AgentTeam5Qty     uint
 	AgentTeam6Qty     uint
+	// Agents is the number of agents of every team, including those without a field of their own
+	Agents      map[string]uint
+	Seed        int64
+	DamageModel string
+	Stamina     StaminaConfig
+	// MonstersPerLevel is the number of monsters each level's health and attack are split between
+	MonstersPerLevel uint
+	Recall           RecallConfig
+	// Succession is who leads once the leader has died, if its manifesto names no living deputy
+	Succession string
+}
+
+// RecallConfig is how confidence votes on the leader are counted and what follows a recall.
+type RecallConfig struct {
+	QuorumPct        uint
+	SupermajorityPct uint
+	CountAbstentions bool
+	CooldownLevels   uint
+	Outcome          string
+}
+
+// StaminaConfig is what fighting costs the agents in stamina and how they recover it.
+type StaminaConfig struct {
+	AttackCost   uint
+	DefendCost   uint
+	ItemWeight   float32
+	CowerStamina uint
+	CowerHpPct   uint
+	RoundRegen   uint
+	LevelRegen   uint
 }
 
 type LevelStages struct {
@@ -73,7 +110,13 @@ type LevelStages struct {
 	FightStage    FightStage
 	LootStage     LootStage
 	HPPoolStage   HPPoolStage
+	// Breaches are the decisions of the leader that went against the policies of its manifesto
+	Breaches []BreachLog `json:",omitempty"`
+	// LeaderChanges are the leaders that left office during the level before the end of their term
+	LeaderChanges []LeaderChangeLog `json:",omitempty"`
 	AgentLogs     map[commons.ID]AgentLog
+	// AgentDeltas are the changes made to each agent's state during the level
+	AgentDeltas map[commons.ID]AgentDelta
 }
 
 type AgentLog struct {
@@ -82,6 +125,16 @@ type AgentLog struct {
 	Properties map[string]float32
 }
 
+// AgentDelta is the net change to an agent's health and stamina by cause, the items it gained and lost,
+// and the cause of its death if it died.
+type AgentDelta struct {
+	Hp          map[string]int   `json:",omitempty"`
+	Stamina     map[string]int   `json:",omitempty"`
+	ItemsGained []commons.ItemID `json:",omitempty"`
+	ItemsLost   []commons.ItemID `json:",omitempty"`
+	Died        string           `json:",omitempty"`
+}
+
 type LevelStats struct {
 	NumberOfAgents       uint
 	SkippedThroughHpPool bool
@@ -89,6 +142,11 @@ type LevelStats struct {
 	HPPool               uint
 	MonsterHealth        uint
 	MonsterAttack        uint
+	Monster              string
+	MonsterAbility       string
+	MonsterStrength      uint
+	// Monsters are all of the level's monsters, the first of which is described above
+	Monsters             []MonsterLog
 	LeaderBeforeElection commons.ID
 	LeaderAfterElection  commons.ID
 	AverageAgentHealth   uint
@@ -97,11 +155,50 @@ type LevelStats struct {
 	AverageAgentAttack   uint
 }
 
+type MonsterLog struct {
+	Name     string
+	Ability  string
+	Strength uint
+	Armour   uint
+	Health   uint
+	Attack   uint
+}
+
 type ElectionStage struct {
 	Occurred  bool
 	Winner    commons.ID
 	Team      string
 	Manifesto ManifestoLog
+	// RunnerUp takes over if the winner dies or is recalled and its manifesto names no living deputy, as the
+	// succession and recall rules decide
+	RunnerUp commons.ID `json:",omitempty"`
+	// Strategy is the voting rule the votes were counted with, and Candidates every agent that stood
+	Strategy   VotingStrategy
+	Candidates map[commons.ID]CandidateLog
+	// Ballots are the ballots cast, and RunoffBallots those cast in the second round of a runoff
+	Ballots       BallotsLog
+	RunoffBallots *BallotsLog `json:",omitempty"`
+	// Counts are the rounds of counting, in order, including the round that chose a runoff's finalists
+	Counts []CountLog
+}
+
+type CandidateLog struct {
+	Team      string
+	Manifesto ManifestoLog
+}
+
+// BallotsLog holds the ballots of a round of voting, keyed by voter unless the game anonymises them,
+// in which case they are listed in order of their contents instead.
+type BallotsLog struct {
+	ByVoter   map[commons.ID][]commons.ID `json:",omitempty"`
+	Anonymous [][]commons.ID              `json:",omitempty"`
+}
+
+// CountLog is a round of counting: the score the voting rule gave each candidate it counted,
+// and the candidates it eliminated.
+type CountLog struct {
+	Scores     map[commons.ID]float64
+	Eliminated []commons.ID `json:",omitempty"`
 }
 
 type ManifestoLog struct {
@@ -109,6 +206,38 @@ type ManifestoLog struct {
 	LootImposition      bool
 	TermLength          uint
 	ThresholdPercentage uint
+	Policies            PolicyLog
+	// Deputy is the agent named to take over if the leader dies or is recalled
+	Deputy commons.ID `json:",omitempty"`
+}
+
+// PolicyLog is what a manifesto commits the leader to for its term. Percentages left at 0 commit to nothing,
+// as do a LootPriority and DefectorPunishment of None.
+type PolicyLog struct {
+	HpPoolDonation     uint
+	TradeTax           uint
+	LootPriority       string
+	CowerCap           uint
+	DefectorPunishment string
+	DefectorFine       uint
+}
+
+// BreachLog is a decision of the leader that went against one of its policies, and the agents it concerned.
+type BreachLog struct {
+	Leader commons.ID
+	Policy string
+	Agents []commons.ID
+}
+
+// LeaderChangeLog is a leader leaving office before the end of its term, for Reason, and who took over.
+type LeaderChangeLog struct {
+	Previous commons.ID
+	// Leader is the agent that took over, empty if none did straight away, and Successor how it was chosen
+	Leader    commons.ID `json:",omitempty"`
+	Reason    string
+	Successor string
+	// Election is the snap election held at the end of the stage the leader died in
+	Election *ElectionStage `json:",omitempty"`
 }
 
 type VONCStage struct {
@@ -117,11 +246,20 @@ type VONCStage struct {
 	Against   uint
 	Abstain   uint
 	Threshold uint
+	// Quorate is set when enough votes were counted for the vote to stand, and Recalled when it went against
+	// the leader, who was replaced by Deputy or, if it is empty, by the winner of Election
+	Quorate  bool
+	Recalled bool
+	Deputy   commons.ID `json:",omitempty"`
+	// Election is the election held after the leader lost the vote
+	Election *ElectionStage `json:",omitempty"`
 }
 
 type FightStage struct {
 	Occurred bool
 	Rounds   []FightLog
+	// Stalemate is the stalemate rule applied if the fight reached the round limit, empty otherwise
+	Stalemate string
 }
 
 type FightLog struct {
@@ -130,11 +268,43 @@ type FightLog struct {
 	CoweringAgents  []commons.ID
 	AttackSum       uint
 	ShieldSum       uint
-	AgentsRemaining uint
+	MonsterAttack   uint
+	// Ability is the monster's ability, and AbilityTriggered whether it had any effect this round
+	Ability          string
+	AbilityTriggered bool
+	AgentsRemaining  uint
+	// Monsters are the level's monsters at the end of the round
+	Monsters []MonsterFightLog
+}
+
+type MonsterFightLog struct {
+	Health uint
+	// AttackSum and ShieldSum are the total attack and defense of the agents that fought the monster
+	AttackSum uint
+	ShieldSum uint
 }
 
 type LootStage struct {
 	Occurred bool
+	// Rounds is the number of discussion rounds before the loot was allocated
+	Rounds     uint
+	Transcript []LootMessageLog
+	// WinningProposal is the proposal the allocation was based on, nil if no proposal got a vote
+	WinningProposal *LootProposalLog
+}
+
+type LootMessageLog struct {
+	Round  uint
+	Sender commons.ID
+	// Recipients is empty for a message broadcast to every other agent
+	Recipients []commons.ID
+	Message    string
+}
+
+type LootProposalLog struct {
+	Proposer commons.ID
+	Votes    uint
+	Rules    []string
 }
 
 type HPPoolStage struct {
@@ -144,40 +314,19 @@ type HPPoolStage struct {
 	NewHPPool        uint
 }
 
-func AgentLogToFile(fields LogField, msg string) {
-	agentLog := AgentLog{}
-	for k, v := range fields {
-		if k == "agentName" {
-			if name, ok := v.(string); ok {
-				agentLog.Name = name
-			}
-		} else if k == "agentID" {
-			if id, ok := v.(string); ok {
-				agentLog.ID = id
-			}
-		} else {
-			if num, ok := v.(float32); ok {
-				agentLog.Properties[k] = num
-			}
-		}
-	}
-	fmt.Println(gameState.CurrentLeader)
-	fmt.Println(fileLog.Levels)
-	fileLog.Levels[gameState.CurrentLevel-1].AgentLogs[agentLog.ID] = agentLog
-}
-
-func LogToFile(lvl Level, fields LogField, msg string, level LevelStages) {
+// LogToFile records an error or warning against the game log, or appends a completed level at Info.
+func (l *GameLog) LogToFile(lvl Level, fields LogField, msg string, level LevelStages) {
 	switch lvl {
 	case Error:
-		fileLog.Errors = append(fileLog.Errors, CombineMessageToFields(fields, msg))
+		l.Errors = append(l.Errors, CombineMessageToFields(fields, msg))
 		Log(lvl, fields, msg)
 		return
 	case Warn:
-		fileLog.Warnings = append(fileLog.Warnings, CombineMessageToFields(fields, msg))
+		l.Warnings = append(l.Warnings, CombineMessageToFields(fields, msg))
 		Log(lvl, fields, msg)
 		return
 	case Info:
-		fileLog.Levels = append(fileLog.Levels, level)
+		l.Levels = append(l.Levels, level)
 		return
 	}
 }
@@ -192,8 +341,10 @@ func CombineMessageToFields(fields LogField, msg string) LogField {
 	return fields
 }
 
-func OutputLog(outcome Outcome) {
-	fileLog.Outcome = outcome
-	logJSON, _ := json.Marshal(fileLog)
-	os.WriteFile("logs/"+runID+".json", logJSON, 0644)
+// OutputLog writes the game log to logs/<runID>.json.
+func OutputLog(runID string, gameLog GameLog) {
+	logJSON, _ := json.Marshal(gameLog)
+	if err := os.WriteFile("logs/"+runID+".json", logJSON, 0644); err != nil {
+		Log(Error, nil, fmt.Sprintf("Unable to write game log: %v", err))
+	}
 }
@@ -2,14 +2,12 @@ package logging
 
 import (
 	"fmt"
-	"infra/game/state"
 	"os"
 
 	"github.com/sirupsen/logrus"
 )
 
 var log = logrus.New()
-var gameState *state.State
 
 type LogField = logrus.Fields
 
@@ -23,7 +21,7 @@ const (
 	Error
 )
 
-func InitLogger(useJSONFormatter bool, debug bool, id string, state *state.State) {
+func InitLogger(useJSONFormatter bool, debug bool) {
 	if useJSONFormatter {
 		log.SetFormatter(&logrus.JSONFormatter{})
 	} else {
@@ -31,17 +29,31 @@ func InitLogger(useJSONFormatter bool, debug bool, id string, state *state.State
 	}
 
 	log.SetOutput(os.Stdout)
-	runID = id
-	gameState = state
 
 	if debug {
 		log.SetLevel(logrus.TraceLevel)
 	} else {
-		LogToFile(Warn, nil, "'Trace' and 'Debug' messages hidden. Run with '-d' or 'make runDebug' to see these logs.", LevelStages{})
+		Log(Warn, nil, "'Trace' and 'Debug' messages hidden. Run with '-d' or 'make runDebug' to see these logs.")
 		log.SetLevel(logrus.InfoLevel)
 	}
 }
 
+// SetLevel hides all messages below lvl, e.g. to keep batch runs quiet.
+func SetLevel(lvl Level) {
+	switch lvl {
+	case Trace:
+		log.SetLevel(logrus.TraceLevel)
+	case Debug:
+		log.SetLevel(logrus.DebugLevel)
+	case Info:
+		log.SetLevel(logrus.InfoLevel)
+	case Warn:
+		log.SetLevel(logrus.WarnLevel)
+	default:
+		log.SetLevel(logrus.ErrorLevel)
+	}
+}
+
 func Log(lvl Level, fields LogField, msg string) {
 	switch lvl {
 	case Trace:
@@ -1,197 +1,149 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"math/rand"
-	"sort"
-
-	"github.com/google/uuid"
+	"os"
+	"strconv"
+	"strings"
 
 	"infra/config"
-	"infra/game/agent"
 	"infra/game/commons"
-	"infra/game/decision"
-	gamemath "infra/game/math"
-	"infra/game/message"
-	"infra/game/stage/election"
-	"infra/game/stage/fight"
-	"infra/game/stages"
-	"infra/game/state"
+	"infra/game/engine"
+	"infra/game/stage/initialise"
 	"infra/logging"
+	"infra/teams"
 
-	"github.com/benbjohnson/immutable"
 	"github.com/joho/godotenv"
-	"golang.org/x/exp/constraints"
-)
-
-/*
-	Package Variables
-*/
-
-var (
-	viewPtr     = &state.View{}
-	globalState *state.State
-	agentMap    map[commons.ID]agent.Agent
-	gameConfig  *config.GameConfig
 )
 
-/*
-	Init Helpers
-*/
-
-func updateView(ptr *state.View, globalState *state.State) {
-	*ptr = globalState.ToView()
+// seedOverride returns the -seed flag value if it was explicitly set, nil otherwise.
+func seedOverride(seed int64) *int64 {
+	var override *int64
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			override = &seed
+		}
+	})
+	return override
 }
 
-func initGame() {
+// loadEnv loads .env, if present.
+func loadEnv() {
 	if godotenv.Load() != nil {
 		logging.Log(logging.Error, nil, "No .env file located, using defaults")
 	}
+}
 
-	stages.Mode = config.EnvToString("MODE", "default")
-
-	initGameConfig := stages.InitGameConfig()
-	gameConfig = &initGameConfig
-	defStrategyMap := stages.ChooseDefaultStrategyMap(InitAgentMap)
-	numAgents, agents, agentStateMap, inventoryMap := stages.InitAgents(defStrategyMap, initGameConfig, viewPtr)
-	gameConfig.InitialNumAgents = numAgents
+// configFlags are the flags choosing the game configuration, shared by the commands that start games.
+type configFlags struct {
+	file      *string
+	overrides *parameterFlag
+}
 
-	globalState = &state.State{
-		MonsterHealth: gamemath.CalculateMonsterHealth(gameConfig.InitialNumAgents, gameConfig.Stamina, gameConfig.NumLevels, 1),
-		MonsterAttack: gamemath.CalculateMonsterDamage(gameConfig.InitialNumAgents, gameConfig.StartingHealthPoints, gameConfig.Stamina, gameConfig.ThresholdPercentage, gameConfig.NumLevels, 1),
-		AgentState:    agentStateMap,
-		InventoryMap:  inventoryMap,
-		Defection:     gameConfig.Defection,
+func addConfigFlags(set *flag.FlagSet) configFlags {
+	flags := configFlags{
+		file:      set.String("config", "", "JSON config file to apply on top of the environment, see config.File"),
+		overrides: &parameterFlag{},
 	}
-	agentMap = agents
+	set.Var(flags.overrides, "set", "Set a game parameter, e.g. -set LEVELS=20 or -set AGENT_TEAM1_QUANTITY=50, overriding the config file. May be repeated")
+	return flags
 }
 
-/*
-	Communication Helpers
-*/
-
-func addCommsChannels() map[commons.ID]chan message.TaggedMessage {
-	keys := make([]commons.ID, len(agentMap))
-	res := make(map[commons.ID]chan message.TaggedMessage)
-	i := 0
-	for k := range agentMap {
-		keys[i] = k
-		i++
-	}
+// parameterFlag collects NAME=VALUE game parameters, as taken by config.GameConfig.SetParameter.
+type parameterFlag []string
 
-	for _, key := range keys {
-		res[key] = make(chan message.TaggedMessage, 100)
-	}
-	immutableMap := createImmutableMapForChannels(res)
-	for id, a := range agentMap {
-		a.SetCommunication(agent.NewCommunication(res[id], *immutableMap.Delete(id)))
-	}
-	return res
+func (p *parameterFlag) String() string {
+	return strings.Join(*p, " ")
 }
 
-func createImmutableMapForChannels[K constraints.Ordered, V any](peerChannels map[K]chan V) immutable.Map[K, chan<- V] {
-	builder := immutable.NewMapBuilder[K, chan<- V](nil)
-	for pID, channel := range peerChannels {
-		builder.Set(pID, channel)
+func (p *parameterFlag) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("%q is not of the form NAME=VALUE", value)
 	}
-	return *builder.Map()
-}
-
-/*
-	Election Helpers
-*/
-
-func runElection() uint {
-	electedAgent, manifesto := election.HandleElection(globalState, agentMap, decision.VotingStrategy(gameConfig.VotingStrategy), gameConfig.VotingPreferences)
-	termLeft := manifesto.TermLength()
-	globalState.LeaderManifesto = manifesto
-	globalState.CurrentLeader = electedAgent
-	updateView(viewPtr, globalState)
-	return termLeft
+	*p = append(*p, value)
+	return nil
 }
 
-func runConfidenceVote(termLeft uint) (uint, map[decision.Intent]uint) {
-	votes := make(map[decision.Intent]uint)
-	for _, a := range agentMap {
-		votes[a.Strategy.HandleConfidencePoll(*a.BaseAgent)]++
+// loadGameConfig reads the game configuration from the environment (and .env, if present), then applies the
+// config file and parameters given by flags, and exits if the result is not a valid game.
+func loadGameConfig(flags configFlags, seed *int64) config.GameConfig {
+	loadEnv()
+	// the flags win over the environment, but export them into it too so that reading it doesn't warn that the
+	// parameters they set are unset
+	for _, parameter := range *flags.overrides {
+		key, value, _ := strings.Cut(parameter, "=")
+		os.Setenv(key, value)
 	}
-	leader := agentMap[globalState.CurrentLeader]
-	leaderName := leader.BaseAgent.Name()
-
-	logging.Log(logging.Info, logging.LogField{
-		"positive":  votes[decision.Positive],
-		"negative":  votes[decision.Negative],
-		"abstain":   votes[decision.Abstain],
-		"threshold": globalState.LeaderManifesto.OverthrowThreshold(),
-		"leader":    globalState.CurrentLeader,
-		"team":      leaderName,
-	}, "Confidence Vote")
-
-	if votes[decision.Negative]+votes[decision.Positive] == 0 {
-		return termLeft, votes
-	} else if 100*votes[decision.Negative]/(votes[decision.Negative]+votes[decision.Positive]) > globalState.LeaderManifesto.OverthrowThreshold() {
-		logging.Log(logging.Info, nil, fmt.Sprintf("%s got ousted", globalState.CurrentLeader))
-		termLeft = runElection()
+	if seed != nil {
+		os.Setenv("SEED", strconv.FormatInt(*seed, 10))
 	}
-	return termLeft, votes
-}
 
-/*
-	Fight Helpers
-*/
-
-func damageCalculation(fightRoundResult decision.FightResult) {
-	if len(fightRoundResult.CoweringAgents) != len(agentMap) {
-		globalState.MonsterHealth = commons.SaturatingSub(globalState.MonsterHealth, fightRoundResult.AttackSum)
-		if globalState.MonsterHealth > 0 && fightRoundResult.ShieldSum < globalState.MonsterAttack {
-			agentsFighting := append(fightRoundResult.AttackingAgents, fightRoundResult.ShieldingAgents...)
-			damageTaken := globalState.MonsterAttack - fightRoundResult.ShieldSum
-			fight.DealDamage(damageTaken, agentsFighting, agentMap, globalState)
-			// TODO: Monster disruptive ability
+	gameConfig := initialise.InitGameConfig()
+	if monsterFile := config.EnvToString("MONSTER_FILE", ""); monsterFile != "" {
+		if err := gameConfig.SetParameter("MONSTER_FILE", monsterFile); err != nil {
+			logging.Log(logging.Error, logging.LogField{"error": err}, "Could not load the monster definition file")
+			os.Exit(1)
+		}
+	}
+	if *flags.file != "" {
+		if err := gameConfig.LoadFile(*flags.file); err != nil {
+			logging.Log(logging.Error, logging.LogField{"error": err}, "Could not load the config file")
+			os.Exit(2)
 		}
-	} else {
-		damageTaken := globalState.MonsterAttack
-		fight.DealDamage(damageTaken, fightRoundResult.CoweringAgents, agentMap, globalState)
 	}
-	*viewPtr = globalState.ToView()
+	for _, parameter := range *flags.overrides {
+		key, value, _ := strings.Cut(parameter, "=")
+		if err := gameConfig.SetParameter(key, value); err != nil {
+			logging.Log(logging.Error, logging.LogField{"error": err}, "Could not set a game parameter")
+			os.Exit(2)
+		}
+	}
+	if seed != nil {
+		gameConfig.Seed = *seed
+	}
+	if err := gameConfig.Validate(commons.SortedKeys(teams.Strategies())); err != nil {
+		logging.Log(logging.Error, nil, err.Error())
+		os.Exit(2)
+	}
+	logging.Log(logging.Info, logging.LogField{"seed": gameConfig.Seed}, "Seeding game random source")
+
+	return gameConfig
 }
 
-/*
-	Hp Pool Helpers
-*/
-
-func checkHpPool() bool {
-	if globalState.HpPool >= globalState.MonsterHealth {
-		logging.Log(logging.Info, logging.LogField{
-			"Original HP Pool":  globalState.HpPool,
-			"Monster Health":    globalState.MonsterHealth,
-			"HP Pool Remaining": globalState.HpPool - globalState.MonsterHealth,
-		}, fmt.Sprintf("Skipping level %d through HP Pool", globalState.CurrentLevel))
-
-		globalState.HpPool -= globalState.MonsterHealth
-		globalState.MonsterHealth = 0
-		return true
+// resumeGame loads a game from a checkpoint written by saveCheckpoint. The game's configuration comes from
+// the checkpoint, not the environment.
+func resumeGame(path string) *engine.Game {
+	f, err := os.Open(path)
+	if err != nil {
+		logging.Log(logging.Error, logging.LogField{"error": err}, "Could not open the checkpoint")
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	game, err := engine.Resume(f, teams.Strategies())
+	if err != nil {
+		logging.Log(logging.Error, logging.LogField{"error": err}, "Could not resume from the checkpoint")
+		os.Exit(1)
 	}
-	return false
+	logging.Log(logging.Info, logging.LogField{"checkpoint": path, "level": game.Level()}, "Resuming game")
+	return game
 }
 
-func generateLootPool(numAgents int, currentLevel uint) *state.LootPool {
-	makeItems := func() *commons.ImmutableList[state.Item] {
-		nItems := rand.Intn(numAgents) / 10
-		items := make([]state.Item, nItems)
-		for i := 0; i < nItems; i++ {
-			items[i] = *state.NewItem(uuid.NewString(), currentLevel*uint(rand.Intn(3)+1))
+// saveCheckpoint writes a checkpoint of the game to path, replacing any earlier one only once it is complete.
+func saveCheckpoint(game *engine.Game, path string) {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err == nil {
+		err = game.Checkpoint(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
 		}
-		sort.SliceStable(items, func(i, j int) bool {
-			return items[i].Value() > items[j].Value()
-		})
-		return commons.NewImmutableList(items)
 	}
-
-	return state.NewLootPool(
-		makeItems(),
-		makeItems(),
-		makeItems(),
-		makeItems(),
-	)
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		logging.Log(logging.Error, logging.LogField{"error": err, "level": game.Level()}, "Could not save a checkpoint")
+	}
 }
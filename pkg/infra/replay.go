@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+
+	"infra/game/engine"
+	"infra/logging"
+)
+
+// runReplay implements the `replay` command: play a game again from its event log, without its strategies,
+// and check that the engine reaches the same state at the end of every level.
+func runReplay(args []string) {
+	set := flag.NewFlagSet("replay", flag.ExitOnError)
+	debug := set.Bool("d", false, "Show the engine logs of the replayed game")
+	set.Usage = func() {
+		fmt.Fprintf(set.Output(), "Usage: %s replay [flags] EVENT_LOG\n", os.Args[0])
+		set.PrintDefaults()
+	}
+	_ = set.Parse(args)
+	if set.NArg() != 1 {
+		set.Usage()
+		os.Exit(2)
+	}
+
+	logging.InitLogger(false, *debug)
+	if !*debug {
+		logging.SetLevel(logging.Error)
+	}
+
+	file, err := os.Open(set.Arg(0))
+	if err != nil {
+		logging.Log(logging.Error, nil, fmt.Sprintf("Unable to open event log: %v", err))
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	levels, err := engine.Replay(bufio.NewReader(file))
+	if err != nil {
+		fmt.Printf("Replay diverged after %d levels: %v\n", levels, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Replayed %d levels, matching the event log\n", levels)
+}
+
+// recordEvents starts recording the game's event log to path, exiting on failure.
+// The returned function finishes writing the log.
+func recordEvents(game *engine.Game, path string) func() {
+	file, err := os.Create(path)
+	if err != nil {
+		logging.Log(logging.Error, nil, fmt.Sprintf("Unable to create event log: %v", err))
+		os.Exit(1)
+	}
+	writer := bufio.NewWriter(file)
+	game.RecordEvents(writer)
+
+	return func() {
+		err := game.EventsErr()
+		if err == nil {
+			err = writer.Flush()
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			logging.Log(logging.Error, nil, fmt.Sprintf("Unable to write event log: %v", err))
+		}
+	}
+}
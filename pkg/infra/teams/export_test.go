@@ -0,0 +1,10 @@
+package teams
+
+// Unregister removes the team registered under name, so that tests can register teams of their own
+// without leaving them behind for the next run.
+func Unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	delete(registry, name)
+}
@@ -0,0 +1,7 @@
+package teams
+
+import "infra/game/example"
+
+func init() {
+	Register("RANDOM", example.NewRandomAgent, Options{})
+}
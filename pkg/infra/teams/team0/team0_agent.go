@@ -1,18 +1,15 @@
 package team0
 
 import (
-	"math/rand"
-
 	"infra/game/agent"
-	"infra/game/commons"
 	"infra/game/decision"
+	"infra/game/example"
 	"infra/game/message"
-	"infra/game/state"
-
-	"github.com/benbjohnson/immutable"
 )
 
+// ProbabilisticAgent fights with fixed probabilities, and otherwise plays like example.RandomAgent.
 type ProbabilisticAgent struct {
+	example.RandomAgent
 	fightDecisionCDF []float32
 }
 
@@ -36,11 +33,11 @@ func NewProbabilisticAgent(pCower float32, pAttack float32, pDefend float32) *Pr
 	return &ProbabilisticAgent{fightDecisionCDF: cdf}
 }
 
-func (r ProbabilisticAgent) HandleFightMessage(m message.TaggedMessage, view *state.View, agent agent.BaseAgent, log *immutable.Map[commons.ID, decision.FightAction]) decision.FightAction {
-	dice := rand.Float32()
+func (r *ProbabilisticAgent) FightActionNoProposal(baseAgent agent.BaseAgent) decision.FightAction {
+	dice := baseAgent.Rand().Float32()
 
 	fight := 0
-	for dice > r.fightDecisionCDF[fight] {
+	for fight < len(r.fightDecisionCDF)-1 && dice > r.fightDecisionCDF[fight] {
 		fight++
 	}
 	switch fight {
@@ -52,3 +49,11 @@ func (r ProbabilisticAgent) HandleFightMessage(m message.TaggedMessage, view *st
 		return decision.Defend
 	}
 }
+
+func (r *ProbabilisticAgent) FightAction(
+	baseAgent agent.BaseAgent,
+	_ decision.FightAction,
+	_ message.Proposal[decision.FightAction],
+) decision.FightAction {
+	return r.FightActionNoProposal(baseAgent)
+}
@@ -23,30 +23,30 @@ import (
 	"github.com/yourbasic/graph/build"
 )
 
-var currentAgent *SocialAgent
-var sortedAgentIDs []string
-var agents map[commons.ID]agent.Agent
-
-var gridWidth int
+// network is the grid the social agents of a game are connected in. Each game has its own, shared by its social
+// agents, so that games can be played side by side.
+type network struct {
+	// sortedAgentIDs are the agents in the grid, in the order of their graphIDs
+	sortedAgentIDs []string
+	gridWidth      int
+}
 
-func createConnection(w int, _ int64) bool {
-	if w < len(sortedAgentIDs) {
-		peerAgent := sortedAgentIDs[w]
+// connect trusts the peer at position w in the grid.
+func (n *network) connect(currentAgent *SocialAgent, w int) {
+	if w < len(n.sortedAgentIDs) {
+		peerAgent := n.sortedAgentIDs[w]
 		sci := currentAgent.socialCapital[peerAgent]
 		sci[1] = 0.8
 		currentAgent.socialCapital[peerAgent] = sci
 	}
-
-	return false
 }
 
 func connectAgents(agentMap map[commons.ID]agent.Agent) {
-	agents = agentMap
 	numAgents := len(agentMap)
 	// Create a grid graph of all the agents. Most agents would
 	// be connected to 4 other agents
 	gridHeight := 10
-	gridWidth = numAgents / gridHeight
+	gridWidth := numAgents / gridHeight
 	if numAgents%gridHeight != 0 {
 		gridWidth++
 	}
@@ -65,19 +65,35 @@ func connectAgents(agentMap map[commons.ID]agent.Agent) {
 		agentIDs = append(agentIDs, k)
 	}
 	sort.Strings(agentIDs)
-	sortedAgentIDs = agentIDs
+	net := &network{sortedAgentIDs: agentIDs, gridWidth: gridWidth}
 	for i, k := range agentIDs {
-		currentAgent = agents[k].Strategy.(*SocialAgent)
+		currentAgent := agentMap[k].Strategy.(*SocialAgent)
 		currentAgent.graphID = i
-		combined.Visit(i, createConnection)
+		currentAgent.network = net
+		combined.Visit(i, func(w int, _ int64) bool {
+			net.connect(currentAgent, w)
+			return false
+		})
 	}
+}
 
+// resetGraphPictures empties the directory printGraph draws into, ready for a new game.
+func resetGraphPictures() {
 	os.RemoveAll("./pkg/infra/teams/team1/graph/pics/")
 	os.MkdirAll("./pkg/infra/teams/team1/graph/pics/", os.ModePerm)
 }
 
 func printGraph(agentMap map[commons.ID]agent.Agent, state *state.State) {
-	numInitialAgents := len(sortedAgentIDs)
+	var net *network
+	for _, a := range agentMap {
+		net = a.Strategy.(*SocialAgent).network
+		break
+	}
+	if net == nil {
+		return
+	}
+	gridWidth := net.gridWidth
+	numInitialAgents := len(net.sortedAgentIDs)
 	aliveAgents := map[int]bool{}
 	for i := 0; i < numInitialAgents; i++ {
 		aliveAgents[i] = false
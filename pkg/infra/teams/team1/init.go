@@ -10,83 +10,28 @@
 package team1
 
 import (
-	"infra/config"
 	"infra/game/agent"
 	"infra/game/commons"
-	"infra/game/decision"
-	"infra/game/stage/initialise"
-	"infra/game/state"
-	"infra/logging"
-	"math/rand"
-	"sync"
-	"time"
-
-	"github.com/benbjohnson/immutable"
+	"infra/teams"
 )
 
-var InitAgentMap = map[commons.ID]func() agent.Strategy{
-	"SocialAgent": NewSocialAgent,
+func init() {
+	teams.Register("TEAM1", NewSocialAgent, teams.Options{Init: initSocialAgents})
 }
 
-func InitAgents(defaultStrategyMap map[commons.ID]func() agent.Strategy, gameConfig config.GameConfig, ptr *state.View) (numAgents uint, agentMap map[commons.ID]agent.Agent, agentStateMap map[commons.ID]state.AgentState, inventoryMap state.InventoryMap) {
-	// Initialise a random seed
-	rand.Seed(time.Now().UnixNano())
-	agentMap = make(map[commons.ID]agent.Agent)
-	agentStateMap = make(map[commons.ID]state.AgentState)
-	inventoryMap = state.InventoryMap{
-		Weapons: make(map[commons.ItemID]uint),
-		Shields: make(map[commons.ItemID]uint),
-	}
-
-	numAgents = 0
-
-	for agentName, strategy := range defaultStrategyMap {
-		expectedEnvName := "AGENT_" + agentName + "_QUANTITY"
-		quantity := config.EnvToUint(expectedEnvName, 100)
-
-		numAgents += quantity
-		initialise.InstantiateAgent(gameConfig, agentMap, agentStateMap, quantity, strategy, agentName, ptr)
-	}
-
-	// intit agent's social cap info
-	allAgents := make([]string, 0, len(agentMap))
-	for k := range agentMap {
+// initSocialAgents gives each social agent an empty social capital for every agent in the game,
+// and connects the social agents into a network.
+// To create pictures for the video (takes more time), call resetGraphPictures here and register an Update hook
+// calling printGraph.
+func initSocialAgents(own map[commons.ID]agent.Agent, all map[commons.ID]agent.Agent) {
+	allAgents := make([]string, 0, len(all))
+	for k := range all {
 		allAgents = append(allAgents, k)
 	}
-	for _, a := range agentMap {
+	for _, a := range own {
 		socialStrategy := a.Strategy.(*SocialAgent)
 		socialStrategy.initSocialCapital(allAgents)
+		socialStrategy.selfishness = a.BaseAgent.Rand().Float64()
 	}
-	connectAgents(agentMap)
-
-	return
-}
-
-func UpdateInternalStates(agentMap map[commons.ID]agent.Agent, globalState *state.State, immutableFightRounds *commons.ImmutableList[decision.ImmutableFightResult], votesResult *immutable.Map[decision.Intent, uint]) map[commons.ID]logging.AgentLog {
-	var wg sync.WaitGroup
-	agentLogChan := make(chan logging.AgentLog)
-	for id, a := range agentMap {
-		id := id
-		a := a
-		wg.Add(1)
-		go func(wait *sync.WaitGroup) {
-			a.HandleUpdateInternalState(globalState.AgentState[id], immutableFightRounds, votesResult, agentLogChan)
-			wait.Done()
-		}(&wg)
-	}
-
-	agentLogs := make(map[commons.ID]logging.AgentLog)
-	go func(agentLogChan chan logging.AgentLog, agentLogs map[commons.ID]logging.AgentLog) {
-		for log := range agentLogChan {
-			agentLogs[log.ID] = log
-		}
-	}(agentLogChan, agentLogs)
-	wg.Wait()
-	// fmt.Println(agentLogs)
-	close(agentLogChan)
-	// fmt.Println(agentLogs)
-
-	// Uncomment to create pictures for the video (takes more time)
-	// printGraph(agentMap, globalState)
-	return agentLogs
+	connectAgents(own)
 }
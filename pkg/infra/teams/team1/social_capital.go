@@ -2,6 +2,7 @@ package team1
 
 import (
 	"infra/game/agent"
+	"infra/game/commons"
 	"infra/game/decision"
 	"infra/game/message"
 	"infra/teams/team1/internal"
@@ -89,16 +90,22 @@ func (s *SocialAgent) sendGossip(agent agent.BaseAgent) {
 	selfID := agent.ID()
 
 	sortedSCTrustHonor := make([]SocialCapInfo, 0, len(s.socialCapital))
-	for k, sc := range s.socialCapital {
+	for _, k := range commons.SortedKeys(s.socialCapital) {
 		if k == selfID { // Exclude self
 			continue
 		}
-		sci := SocialCapInfo{ID: k, arr: sc}
+		sci := SocialCapInfo{ID: k, arr: s.socialCapital[k]}
 		sortedSCTrustHonor = append(sortedSCTrustHonor, sci)
 	}
 
-	sort.Slice(sortedSCTrustHonor, func(i int, j int) bool {
-		return (sortedSCTrustHonor[i].arr[2] + sortedSCTrustHonor[i].arr[3]) > (sortedSCTrustHonor[j].arr[2] + sortedSCTrustHonor[j].arr[3])
+	// Agents with the same trust and honour stay in ID order, so that runs with the same seed gossip alike
+	sort.SliceStable(sortedSCTrustHonor, func(i int, j int) bool {
+		scoreI := sortedSCTrustHonor[i].arr[2] + sortedSCTrustHonor[i].arr[3]
+		scoreJ := sortedSCTrustHonor[j].arr[2] + sortedSCTrustHonor[j].arr[3]
+		if scoreI != scoreJ {
+			return scoreI > scoreJ
+		}
+		return sortedSCTrustHonor[i].ID < sortedSCTrustHonor[j].ID
 	})
 
 	numAdmire := int(s.propAdmire * float64(len(sortedSCTrustHonor)))
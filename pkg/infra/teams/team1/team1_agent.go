@@ -27,6 +27,8 @@ type SocialAgent struct {
 	propAdmire float64
 
 	graphID int // for logging
+	// network is the grid the game's social agents are connected in
+	network *network
 }
 
 func (s *SocialAgent) FightResolution(
@@ -38,7 +40,7 @@ func (s *SocialAgent) FightResolution(
 	builder := immutable.NewMapBuilder[commons.ID, decision.FightAction](nil)
 	for _, id := range commons.ImmutableMapKeys(view.AgentState()) {
 		var fightAction decision.FightAction
-		switch rand.Intn(3) {
+		switch agent.Rand().Intn(3) {
 		case 0:
 			fightAction = decision.Attack
 		case 1:
@@ -67,9 +69,9 @@ func (s *SocialAgent) FightActionNoProposal(baseAgent agent.BaseAgent) decision.
 	if exploration != "" {
 		epsilon, _ := strconv.ParseFloat(exploration, 64)
 
-		if epsilon < rand.Float64() {
+		if epsilon < baseAgent.Rand().Float64() {
 			// Do random action
-			return decision.FightAction(rand.Intn(3))
+			return decision.FightAction(baseAgent.Rand().Intn(3))
 		}
 	}
 
@@ -94,13 +96,13 @@ func (s *SocialAgent) HandleLootInformation(m message.TaggedInformMessage[messag
 	//agent.AgentState().Hp
 }
 
-func (s *SocialAgent) HandleLootRequest(m message.TaggedRequestMessage[message.LootRequest]) message.LootInform {
+func (s *SocialAgent) HandleLootRequest(m message.TaggedRequestMessage[message.LootRequest], _ agent.BaseAgent) message.LootInform {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (s *SocialAgent) HandleLootProposal(_ message.Proposal[decision.LootAction], _ agent.BaseAgent) decision.Intent {
-	switch rand.Intn(3) {
+func (s *SocialAgent) HandleLootProposal(_ message.Proposal[decision.LootAction], baseAgent agent.BaseAgent) decision.Intent {
+	switch baseAgent.Rand().Intn(3) {
 	case 0:
 		return decision.Positive
 	case 1:
@@ -110,8 +112,8 @@ func (s *SocialAgent) HandleLootProposal(_ message.Proposal[decision.LootAction]
 	}
 }
 
-func (s *SocialAgent) HandleLootProposalRequest(_ message.Proposal[decision.LootAction], _ agent.BaseAgent) bool {
-	switch rand.Intn(2) {
+func (s *SocialAgent) HandleLootProposalRequest(_ message.Proposal[decision.LootAction], baseAgent agent.BaseAgent) bool {
+	switch baseAgent.Rand().Intn(2) {
 	case 0:
 		return true
 	default:
@@ -128,13 +130,13 @@ func (s *SocialAgent) LootAllocation(
 	view := ba.View()
 	ids := commons.ImmutableMapKeys(view.AgentState())
 	iterator := ba.Loot().Weapons().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(ba.Rand(), iterator, ids, lootAllocation)
 	iterator = ba.Loot().Shields().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(ba.Rand(), iterator, ids, lootAllocation)
 	iterator = ba.Loot().HpPotions().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(ba.Rand(), iterator, ids, lootAllocation)
 	iterator = ba.Loot().StaminaPotions().Iterator()
-	allocateRandomly(iterator, ids, lootAllocation)
+	allocateRandomly(ba.Rand(), iterator, ids, lootAllocation)
 	mMapped := make(map[commons.ID]immutable.SortedMap[commons.ItemID, struct{}])
 	for id, itemIDS := range lootAllocation {
 		mMapped[id] = commons.ListToImmutableSortedSet(itemIDS)
@@ -142,10 +144,10 @@ func (s *SocialAgent) LootAllocation(
 	return commons.MapToImmutable(mMapped)
 }
 
-func allocateRandomly(iterator commons.Iterator[state.Item], ids []commons.ID, lootAllocation map[commons.ID][]commons.ItemID) {
+func allocateRandomly(rng *rand.Rand, iterator commons.Iterator[state.Item], ids []commons.ID, lootAllocation map[commons.ID][]commons.ItemID) {
 	for !iterator.Done() {
 		next, _ := iterator.Next()
-		toBeAllocated := ids[rand.Intn(len(ids))]
+		toBeAllocated := ids[rng.Intn(len(ids))]
 		if l, ok := lootAllocation[toBeAllocated]; ok {
 			l = append(l, next.Id())
 			lootAllocation[toBeAllocated] = l
@@ -176,8 +178,8 @@ func (s *SocialAgent) CreateManifesto(_ agent.BaseAgent) *decision.Manifesto {
 	return manifesto
 }
 
-func (s *SocialAgent) HandleConfidencePoll(_ agent.BaseAgent) decision.Intent {
-	switch rand.Intn(3) {
+func (s *SocialAgent) HandleConfidencePoll(baseAgent agent.BaseAgent) decision.Intent {
+	switch baseAgent.Rand().Intn(3) {
 	case 0:
 		return decision.Abstain
 	case 1:
@@ -195,7 +197,7 @@ func (s *SocialAgent) HandleFightInformation(m message.TaggedInformMessage[messa
 	case message.ArrayInfo:
 		s.receiveGossip(m.Message().(message.ArrayInfo), m.Sender())
 	}
-	makesProposal := rand.Intn(100)
+	makesProposal := baseAgent.Rand().Intn(100)
 	if makesProposal > 80 {
 		rules := make([]proposal.Rule[decision.FightAction], 0)
 
@@ -243,9 +245,9 @@ func (s *SocialAgent) HandleElectionBallot(b agent.BaseAgent, _ *decision.Electi
 	// Randomly fill the ballot
 	var ballot decision.Ballot
 	numAliveAgents := len(aliveAgentIDs)
-	numCandidate := rand.Intn(numAliveAgents)
+	numCandidate := b.Rand().Intn(numAliveAgents)
 	for i := 0; i < numCandidate; i++ {
-		randomIdx := rand.Intn(numAliveAgents)
+		randomIdx := b.Rand().Intn(numAliveAgents)
 		randomCandidate := aliveAgentIDs[uint(randomIdx)]
 		ballot = append(ballot, randomCandidate)
 	}
@@ -253,8 +255,8 @@ func (s *SocialAgent) HandleElectionBallot(b agent.BaseAgent, _ *decision.Electi
 	return ballot
 }
 
-func (s *SocialAgent) HandleFightProposal(_ message.Proposal[decision.FightAction], _ agent.BaseAgent) decision.Intent {
-	intent := rand.Intn(2)
+func (s *SocialAgent) HandleFightProposal(_ message.Proposal[decision.FightAction], baseAgent agent.BaseAgent) decision.Intent {
+	intent := baseAgent.Rand().Intn(2)
 	if intent == 0 {
 		return decision.Positive
 	} else {
@@ -264,10 +266,10 @@ func (s *SocialAgent) HandleFightProposal(_ message.Proposal[decision.FightActio
 
 func (s *SocialAgent) HandleFightProposalRequest(
 	_ message.Proposal[decision.FightAction],
-	_ agent.BaseAgent,
+	baseAgent agent.BaseAgent,
 	_ *immutable.Map[commons.ID, decision.FightAction],
 ) bool {
-	switch rand.Intn(2) {
+	switch baseAgent.Rand().Intn(2) {
 	case 0:
 		return true
 	default:
@@ -293,9 +295,9 @@ func (s *SocialAgent) HandleTradeNegotiation(_ agent.BaseAgent, _ message.TradeI
 	return message.TradeRequest{}
 }
 
+// NewSocialAgent selfishness is drawn from the agent's seeded source in InitAgents
 func NewSocialAgent() agent.Strategy {
 	return &SocialAgent{
-		selfishness:     rand.Float64(),
 		gossipThreshold: 0.5,
 		propAdmire:      0.1,
 		propHate:        0.1,
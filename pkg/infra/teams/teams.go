@@ -0,0 +1,77 @@
+// Package teams is the registry of the strategies agents can play with. Each team package registers its
+// strategy from an init function, and is linked into the game by a blank import in main.
+// How many agents of each team play is set by AGENT_<NAME>_QUANTITY, see config.GameConfig.AgentQuantity.
+package teams
+
+import (
+	"fmt"
+	"sync"
+
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/state"
+)
+
+// Options are the hooks a team can register alongside its strategy, all of which are optional.
+type Options struct {
+	// Init runs once every agent in the game has been created, with the team's own agents and all of them.
+	Init func(own map[commons.ID]agent.Agent, all map[commons.ID]agent.Agent)
+	// Update runs at the end of every completed level, after the team's agents have updated their internal states.
+	Update func(own map[commons.ID]agent.Agent, globalState *state.State)
+}
+
+// Team is a registered strategy.
+type Team struct {
+	Name        string
+	Constructor func() agent.Strategy
+	Options
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Team)
+)
+
+// Register adds a team to the registry under name, which agents of the team are known by in the game and its
+// logs. It panics if the name is already taken, as two teams must not share their agents' quantity.
+func Register(name string, constructor func() agent.Strategy, options Options) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("teams: team %s registered twice", name))
+	}
+	registry[name] = Team{Name: name, Constructor: constructor, Options: options}
+}
+
+// Lookup returns the team registered under name.
+func Lookup(name string) (Team, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	team, ok := registry[name]
+	return team, ok
+}
+
+// Strategies returns the constructor of every registered team, by name, to build a game's population from.
+func Strategies() map[commons.ID]func() agent.Strategy {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	strategies := make(map[commons.ID]func() agent.Strategy, len(registry))
+	for name, team := range registry {
+		strategies[name] = team.Constructor
+	}
+	return strategies
+}
+
+// Members returns the agents of the named team.
+func Members(name string, agents map[commons.ID]agent.Agent) map[commons.ID]agent.Agent {
+	members := make(map[commons.ID]agent.Agent)
+	for id, a := range agents {
+		if a.BaseAgent.Name() == name {
+			members[id] = a
+		}
+	}
+	return members
+}
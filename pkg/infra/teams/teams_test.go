@@ -0,0 +1,43 @@
+package teams_test
+
+import (
+	"testing"
+
+	"infra/game/agent"
+	"infra/game/commons"
+	"infra/game/example"
+	"infra/teams"
+)
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	initialised := false
+	t.Cleanup(func() { teams.Unregister("TEST") })
+	teams.Register("TEST", example.NewRandomAgent, teams.Options{
+		Init: func(map[commons.ID]agent.Agent, map[commons.ID]agent.Agent) { initialised = true },
+	})
+
+	team, ok := teams.Lookup("TEST")
+	if !ok || team.Name != "TEST" || team.Init == nil {
+		t.Fatalf("Lookup(TEST) = %+v, %v; want the registered team", team, ok)
+	}
+	team.Init(nil, nil)
+	if !initialised {
+		t.Errorf("registered Init hook was not kept")
+	}
+	strategies := teams.Strategies()
+	if _, ok := strategies["TEST"]; !ok {
+		t.Errorf("Strategies() = %v; want TEST among them", commons.SortedKeys(strategies))
+	}
+	if _, ok := strategies["RANDOM"]; !ok {
+		t.Errorf("Strategies() = %v; want RANDOM among them", commons.SortedKeys(strategies))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("registering TEST twice did not panic")
+		}
+	}()
+	teams.Register("TEST", example.NewRandomAgent, teams.Options{})
+}
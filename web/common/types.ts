@@ -45,8 +45,13 @@ export interface Errors {
 }
 
 export enum VotingStrategy {
-    SingleChoicePlurality = 1,
-    BordaCount
+    SingleChoicePlurality,
+    BordaCount,
+    InstantRunoff,
+    Approval,
+    Copeland,
+    Schulze,
+    TwoRoundRunoff
 }
 
 export interface Config {
@@ -57,8 +62,10 @@ export interface Config {
     StartingShield: number
     BaseStamina: number
     PassThreshold: number
-    VotingStrategy: number
-    VotingPreferences: VotingStrategy
+    VotingStrategy: VotingStrategy
+    VotingPreferences: number
+    TieBreak: string
+    AnonymousBallots: boolean
     AgentRandomQty: number
     AgentTeam1Qty: number
     AgentTeam2Qty: number
@@ -66,6 +73,31 @@ export interface Config {
     AgentTeam4Qty: number
     AgentTeam5Qty: number
     AgentTeam6Qty: number
+    Agents: Record<string, number>
+    Seed: number
+    DamageModel: string
+    Stamina: StaminaConfig
+    MonstersPerLevel: number
+    Recall: RecallConfig
+    Succession: string
+}
+
+export interface RecallConfig {
+    QuorumPct: number
+    SupermajorityPct: number
+    CountAbstentions: boolean
+    CooldownLevels: number
+    Outcome: string
+}
+
+export interface StaminaConfig {
+    AttackCost: number
+    DefendCost: number
+    ItemWeight: number
+    CowerStamina: number
+    CowerHpPct: number
+    RoundRegen: number
+    LevelRegen: number
 }
 
 export interface LevelStages {
@@ -75,7 +107,18 @@ export interface LevelStages {
     FightStage: FightStage
     LootStage: LootStage
     HPPoolStage: HPPoolStage
+    Breaches?: Array<BreachLog>
+    LeaderChanges?: Array<LeaderChangeLog>
     AgentLogs: Record<string, AgentLog>
+    AgentDeltas: Record<string, AgentDelta>
+}
+
+export interface AgentDelta {
+    Hp?: Record<string, number>
+    Stamina?: Record<string, number>
+    ItemsGained?: Array<string>
+    ItemsLost?: Array<string>
+    Died?: string
 }
 
 export interface AgentLog {
@@ -102,6 +145,16 @@ export interface LevelStats {
     AverageAgentStamina: number
     AverageAgentShield: number
     AverageAgentAttack: number
+    Monsters: Array<MonsterLog>
+}
+
+export interface MonsterLog {
+    Name: string
+    Ability: string
+    Strength: number
+    Armour: number
+    Health: number
+    Attack: number
 }
 
 export interface ElectionStage {
@@ -109,6 +162,27 @@ export interface ElectionStage {
     Winner: string
     Team: string
     Manifesto: ManifestoLog
+    RunnerUp?: string
+    Strategy: VotingStrategy
+    Candidates: Record<string, CandidateLog>
+    Ballots: BallotsLog
+    RunoffBallots?: BallotsLog
+    Counts: Array<CountLog>
+}
+
+export interface CandidateLog {
+    Team: string
+    Manifesto: ManifestoLog
+}
+
+export interface BallotsLog {
+    ByVoter?: Record<string, Array<string> | null>
+    Anonymous?: Array<Array<string> | null>
+}
+
+export interface CountLog {
+    Scores: Record<string, number>
+    Eliminated?: Array<string>
 }
 
 export interface ManifestoLog {
@@ -116,6 +190,31 @@ export interface ManifestoLog {
     LootImposition: boolean
     TermLength: number
     ThresholdPercentage: number
+    Policies: PolicyLog
+    Deputy?: string
+}
+
+export interface PolicyLog {
+    HpPoolDonation: number
+    TradeTax: number
+    LootPriority: string
+    CowerCap: number
+    DefectorPunishment: string
+    DefectorFine: number
+}
+
+export interface BreachLog {
+    Leader: string
+    Policy: string
+    Agents: Array<string> | null
+}
+
+export interface LeaderChangeLog {
+    Previous: string
+    Leader?: string
+    Reason: string
+    Successor: string
+    Election?: ElectionStage
 }
 
 export interface VONCStage {
@@ -124,6 +223,10 @@ export interface VONCStage {
     Against: number
     Abstain: number
     Threshold: number
+    Quorate: boolean
+    Recalled: boolean
+    Deputy?: string
+    Election?: ElectionStage
 }
 
 export interface FightStage {
@@ -138,6 +241,13 @@ export interface FightLog {
     AttackSum: number
     ShieldSum: number
     AgentsRemaining: number
+    Monsters: Array<MonsterFightLog>
+}
+
+export interface MonsterFightLog {
+    Health: number
+    AttackSum: number
+    ShieldSum: number
 }
 
 export interface LootStage {